@@ -30,15 +30,14 @@ func CircleWKT(origin []float64, radius float64, numPoints int) (string, error)
 func CircleGeom(origin []float64, radius float64, numPoints int) (*geos.Geom, error) {
 	e := geos.Geom{}
 
-	r, err := CircleWKT(origin, radius, numPoints)
+	points, err := draw.Circle(origin, radius, numPoints)
 	if err != nil {
 		return &e, err
 	}
 
-	g, err := gctx.NewGeomFromWKT(r)
-	if err != nil {
-		return &e, err
+	if len(points) < 2 {
+		return &e, fmt.Errorf("circle needs at least 2 points, got %v", len(points))
 	}
 
-	return g, nil
+	return gctx.NewLineString(points), nil
 }
@@ -0,0 +1,108 @@
+package geom
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+	geos "github.com/twpayne/go-geos"
+)
+
+type BlendMode int
+
+const (
+	BlendNormal BlendMode = iota
+	BlendMultiply
+	BlendScreen
+)
+
+// Layer is a group of geometries composited as one image, a nil Opacity is fully opaque.
+type Layer struct {
+	Geoms   []*geos.Geom
+	Styles  []Style
+	Opacity *float64
+	Blend   BlendMode
+}
+
+// RenderLayers draws each layer offscreen in order and composites it over the ones before.
+func RenderLayers(layers []Layer, envelope Envelope, width, height int) (*image.RGBA, error) {
+	m := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(m, m.Bounds(), &image.Uniform{color.White}, image.Point{0, 0}, draw.Src)
+
+	for _, l := range layers {
+		buf := image.NewRGBA(m.Bounds())
+		gc := draw2dimg.NewGraphicContext(buf)
+
+		gc.SetDPI(72)
+
+		err := drawStyledGeoms(gc, l.Geoms, l.Styles, envelope, float64(width), float64(height))
+		if err != nil {
+			return m, err
+		}
+
+		opacity := 1.0
+		if l.Opacity != nil {
+			opacity = *l.Opacity
+		}
+
+		Composite(m, buf, opacity, l.Blend)
+	}
+
+	return m, nil
+}
+
+// Composite blends src faded by opacity over dst where they overlap.
+func Composite(dst, src *image.RGBA, opacity float64, mode BlendMode) {
+	if opacity <= 0 {
+		return
+	}
+
+	opacity = math.Min(opacity, 1)
+
+	r := dst.Bounds().Intersect(src.Bounds())
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		si := src.PixOffset(r.Min.X, y)
+		di := dst.PixOffset(r.Min.X, y)
+
+		for x := r.Min.X; x < r.Max.X; x, si, di = x+1, si+4, di+4 {
+			if src.Pix[si+3] == 0 {
+				continue
+			}
+
+			// both are alpha premultiplied
+			as := float64(src.Pix[si+3]) / 255 * opacity
+			ad := float64(dst.Pix[di+3]) / 255
+
+			for c := 0; c < 3; c++ {
+				cs := float64(src.Pix[si+c]) / 255 * opacity
+				cd := float64(dst.Pix[di+c]) / 255
+
+				dst.Pix[di+c] = toByte(blend(mode, cs, cd, as, ad))
+			}
+
+			dst.Pix[di+3] = toByte(as + ad - as*ad)
+		}
+	}
+}
+
+// blend is the separable blend of the w3c compositing spec for premultiplied colours.
+func blend(mode BlendMode, cs, cd, as, ad float64) float64 {
+	switch mode {
+	case BlendMultiply:
+		return cs*(1-ad) + cd*(1-as) + cs*cd
+
+	case BlendScreen:
+		return cs + cd - cs*cd
+
+	case BlendNormal:
+	}
+
+	return cs + cd*(1-as)
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
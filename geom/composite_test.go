@@ -0,0 +1,168 @@
+package geom
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	geos "github.com/twpayne/go-geos"
+)
+
+func TestComposite(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	yellow := color.RGBA{0xFF, 0xFF, 0x00, 0xFF}
+	pureBlue := color.RGBA{0x00, 0x00, 0xFF, 0xFF}
+
+	tests := map[string]struct {
+		dst, src color.RGBA
+		opacity  float64
+		mode     BlendMode
+		expected color.RGBA
+	}{
+		"normal": {
+			dst:      white,
+			src:      red,
+			opacity:  1,
+			mode:     BlendNormal,
+			expected: red,
+		},
+		"normal half": {
+			dst:      white,
+			src:      red,
+			opacity:  0.5,
+			mode:     BlendNormal,
+			expected: color.RGBA{0xFF, 0x80, 0x80, 0xFF},
+		},
+		"multiply": {
+			dst:      yellow,
+			src:      red,
+			opacity:  1,
+			mode:     BlendMultiply,
+			expected: red,
+		},
+		"multiply complement": {
+			dst:      yellow,
+			src:      pureBlue,
+			opacity:  1,
+			mode:     BlendMultiply,
+			expected: color.RGBA{0x00, 0x00, 0x00, 0xFF},
+		},
+		"screen": {
+			dst:      yellow,
+			src:      pureBlue,
+			opacity:  1,
+			mode:     BlendScreen,
+			expected: white,
+		},
+		"screen half": {
+			dst:      color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			src:      red,
+			opacity:  0.5,
+			mode:     BlendScreen,
+			expected: color.RGBA{0x80, 0x00, 0x00, 0xFF},
+		},
+		"invisible": {
+			dst:      yellow,
+			src:      red,
+			mode:     BlendNormal,
+			expected: yellow,
+		},
+		"transparent": {
+			dst:      yellow,
+			src:      color.RGBA{},
+			opacity:  1,
+			mode:     BlendMultiply,
+			expected: yellow,
+		},
+	}
+
+	for tname, tt := range tests {
+		dst := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		draw.Draw(dst, dst.Bounds(), &image.Uniform{tt.dst}, image.Point{0, 0}, draw.Src)
+
+		src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		draw.Draw(src, src.Bounds(), &image.Uniform{tt.src}, image.Point{0, 0}, draw.Src)
+
+		Composite(dst, src, tt.opacity, tt.mode)
+
+		if actual := dst.RGBAAt(1, 1); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+}
+
+func TestRenderLayers(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+
+	wkts := []string{
+		"POLYGON ((0 0, 15 0, 15 60, 0 60, 0 0))",
+		"POLYGON ((10 10, 40 10, 40 40, 10 40, 10 10))",
+		"POLYGON ((20 20, 50 20, 50 50, 20 50, 20 20))",
+	}
+
+	geoms := []*geos.Geom{}
+	for _, wkt := range wkts {
+		g, err := gctx.NewGeomFromWKT(wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		geoms = append(geoms, g)
+	}
+
+	half := 0.5
+	hidden := 0.0
+
+	layers := []Layer{
+		{
+			Geoms:  geoms[:1],
+			Styles: []Style{{FillColor: blue, StrokeColor: blue}},
+		},
+		{
+			Geoms:   geoms[1:],
+			Styles:  []Style{{FillColor: red, StrokeColor: red}},
+			Opacity: &half,
+		},
+		{
+			Geoms:   geoms[:1],
+			Styles:  []Style{{FillColor: red, StrokeColor: red}},
+			Opacity: &hidden,
+		},
+	}
+
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}}
+
+	m, err := RenderLayers(layers, envelope, 60, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	halfRed := color.RGBA{0xFF, 0x80, 0x80, 0xFF}
+
+	tests := map[string]struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		"background": {55, 55, white},
+		"overlap":    {30, 30, halfRed},
+		"single":     {45, 15, halfRed},
+		"beneath":    {5, 5, blue},
+		"blended":    {12, 30, color.RGBA{0xA6, 0x4A, 0x80, 0xFF}},
+	}
+
+	for tname, tt := range tests {
+		if actual := m.RGBAAt(tt.x, tt.y); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+
+	_, err = RenderLayers([]Layer{{Geoms: geoms}}, envelope, 60, 60)
+	if err == nil {
+		t.Error("expected an error for missing styles")
+	}
+
+	err = savePNG("test-output/layers.png", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+package geom
+
+import (
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dpdf"
+	"github.com/llgcode/draw2d/draw2dsvg"
+)
+
+// ContextWrapper is implemented by graphic contexts that wrap another one.
+type ContextWrapper interface {
+	Unwrap() draw2d.GraphicContext
+}
+
+// miterLimiter is implemented by contexts that can set the miter limit of their strokes.
+type miterLimiter interface {
+	SetMiterLimit(limit float64)
+}
+
+// backend returns the context underneath any wrappers.
+func backend(gc draw2d.GraphicContext) draw2d.GraphicContext {
+	for {
+		w, ok := gc.(ContextWrapper)
+		if !ok {
+			return gc
+		}
+
+		gc = w.Unwrap()
+	}
+}
+
+// current returns the drawing state of the context underneath gc.
+func current(gc draw2d.GraphicContext) (*draw2dbase.ContextStack, bool) {
+	switch b := backend(gc).(type) {
+	case *draw2dimg.GraphicContext:
+		return b.Current, true
+	case *draw2dsvg.GraphicContext:
+		return b.Current, true
+	case *draw2dpdf.GraphicContext:
+		return b.Current, true
+	}
+
+	return nil, false
+}
+
+func imageContext(gc draw2d.GraphicContext) (*draw2dimg.GraphicContext, bool) {
+	img, ok := backend(gc).(*draw2dimg.GraphicContext)
+
+	return img, ok
+}
+
+// setMiterLimit passes the limit to the first context that takes one.
+func setMiterLimit(gc draw2d.GraphicContext, limit float64) {
+	if limit <= 0 {
+		limit = defaultMiterLimit
+	}
+
+	for {
+		if m, ok := gc.(miterLimiter); ok {
+			m.SetMiterLimit(limit)
+			return
+		}
+
+		w, ok := gc.(ContextWrapper)
+		if !ok {
+			return
+		}
+
+		gc = w.Unwrap()
+	}
+}
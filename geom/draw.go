@@ -2,17 +2,18 @@ package geom
 
 import (
 	"errors"
+	"fmt"
 	"image/color"
 	"math"
 
 	"github.com/llgcode/draw2d"
-	"github.com/llgcode/draw2d/draw2dimg"
 	"github.com/llgcode/draw2d/draw2dkit"
 	geos "github.com/twpayne/go-geos"
 	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
 )
 
-func DrawString(gc *draw2dimg.GraphicContext, pos []float64, rotation float64, text string) error {
+func DrawString(gc draw2d.GraphicContext, pos []float64, rotation float64, text string) error {
 	radians := rotation * (math.Pi / 180)
 	rm := draw2d.NewRotationMatrix(radians)
 
@@ -27,137 +28,347 @@ func DrawString(gc *draw2dimg.GraphicContext, pos []float64, rotation float64, t
 	return nil
 }
 
-func DrawRune(gc *draw2dimg.GraphicContext, pos []float64, f font.Face, rotation float64, char rune) error {
-	radians := rotation * (math.Pi / 180)
-	rm := draw2d.NewRotationMatrix(radians)
+// DrawRune strokes then fills char from f with its baseline starting at pos.
+func DrawRune(gc draw2d.GraphicContext, pos []float64, f font.Face, rotation float64, char rune) (TextMetrics, error) {
+	return drawRune(gc, pos, f, rotation, char, true, true)
+}
+
+func drawRune(gc draw2d.GraphicContext, pos []float64, f font.Face, rotation float64, char rune, stroke, fill bool) (TextMetrics, error) {
+	dr, mask, maskp, _, ok := f.Glyph(fixed.Point26_6{}, char)
+	if !ok {
+		return TextMetrics{}, fmt.Errorf("font face has no glyph for %q", char)
+	}
 
-	trlx, trly := rm.InverseTransformPoint(pos[0], pos[1])
+	bounds, advance, _ := f.GlyphBounds(char)
+
+	radians := rotation * (math.Pi / 180)
 
 	gc.Save()
+	gc.Translate(pos[0], pos[1])
 	gc.Rotate(radians)
-	gc.StrokeStringAt(string(char), trlx, trly)
-	gc.FillStringAt(string(char), trlx, trly)
+	err := drawGlyph(gc, f, char, dr, mask, maskp, stroke, fill)
 	gc.Restore()
 
-	return nil
+	if err != nil {
+		return TextMetrics{}, err
+	}
+
+	return TextMetrics{
+		Advance: float64(advance) / 64,
+		Bounds: Envelope{
+			Min: []float64{float64(bounds.Min.X) / 64, float64(bounds.Min.Y) / 64},
+			Max: []float64{float64(bounds.Max.X) / 64, float64(bounds.Max.Y) / 64},
+		},
+	}, nil
 }
 
 func DrawPoint(gc draw2d.GraphicContext, g *geos.Geom, radius float64, fillColor color.Color, strokeWidth float64, strokeColor color.Color, scale func(x, y float64) (float64, float64)) error {
-	gc.SetFillColor(fillColor)
-	gc.SetStrokeColor(strokeColor)
-	gc.SetLineWidth(strokeWidth)
+	style := Style{
+		PointRadius: radius,
+		FillColor:   fillColor,
+		StrokeWidth: strokeWidth,
+		StrokeColor: strokeColor,
+	}
+
+	return DrawPointWithStyle(gc, g, style, scale)
+}
+
+func DrawPointWithStyle(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) error {
+	if g.TypeID() == geos.TypeIDMultiPoint {
+		return DrawMultiPointWithStyle(gc, g, style, scale)
+	}
 
 	x := g.X()
 	y := g.Y()
 
 	x, y = scale(x, y)
 
-	draw2dkit.Circle(gc, x, y, radius)
-	gc.FillStroke()
+	if !style.Marker.isCircle() {
+		return DrawMarker(gc, []float64{x, y}, style)
+	}
+
+	gc.SetFillColor(style.fill())
+	gc.SetStrokeColor(style.stroke())
+	gc.SetLineWidth(style.StrokeWidth)
+	style.applyLine(gc)
+
+	draw2dkit.Circle(gc, x, y, style.PointRadius)
+	fillStrokePath(gc, style)
+
+	return nil
+}
+
+func DrawMultiPoint(gc draw2d.GraphicContext, g *geos.Geom, radius float64, fillColor color.Color, strokeWidth float64, strokeColor color.Color, scale func(x, y float64) (float64, float64)) error {
+	style := Style{
+		PointRadius: radius,
+		FillColor:   fillColor,
+		StrokeWidth: strokeWidth,
+		StrokeColor: strokeColor,
+	}
+
+	return DrawMultiPointWithStyle(gc, g, style, scale)
+}
+
+func DrawMultiPointWithStyle(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) error {
+	for i := 0; i < g.NumGeometries(); i++ {
+		p := g.Geometry(i)
+		if p.IsEmpty() {
+			continue
+		}
+
+		err := DrawPointWithStyle(gc, p, style, scale)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func DrawLine(gc draw2d.GraphicContext, g *geos.Geom, lineWidth float64, fillColor color.Color, strokeWidth float64, strokeColor color.Color, scale func(x, y float64) (float64, float64)) error {
-	cs := GetPoints(g)
+	style := Style{
+		LineWidth:   lineWidth,
+		FillColor:   fillColor,
+		StrokeWidth: strokeWidth,
+		StrokeColor: strokeColor,
+	}
 
-	if lineWidth == 0.0 {
-		return errors.New("line width cannot be zero")
+	return DrawLineWithStyle(gc, g, style, scale)
+}
+
+func DrawLineWithStyle(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) error {
+	var lines [][][]float64
+
+	// every part and ring becomes its own subpath
+	for _, part := range GetParts(g) {
+		lines = append(lines, part...)
 	}
 
-	// first line is for the stroke (beneath)
-	gc.SetStrokeColor(strokeColor)
-	gc.SetLineWidth(lineWidth + strokeWidth)
+	return strokeCoordSeqs(gc, lines, style, scale)
+}
 
-	err := lineCoordSeq(gc, cs, scale)
-	if err != nil {
-		return (err)
+func DrawCoordLine(gc draw2d.GraphicContext, lineCoords [][]float64, lineWidth float64, fillColor color.Color, strokeWidth float64, strokeColor color.Color, scale func(x, y float64) (float64, float64)) error {
+	style := Style{
+		LineWidth:   lineWidth,
+		FillColor:   fillColor,
+		StrokeWidth: strokeWidth,
+		StrokeColor: strokeColor,
 	}
-	gc.Stroke()
 
-	// the actual line
-	gc.SetStrokeColor(fillColor)
-	gc.SetLineWidth(lineWidth)
+	return DrawCoordLineWithStyle(gc, lineCoords, style, scale)
+}
 
-	err = lineCoordSeq(gc, cs, scale)
-	if err != nil {
-		return (err)
+func DrawCoordLineWithStyle(gc draw2d.GraphicContext, lineCoords [][]float64, style Style, scale func(x, y float64) (float64, float64)) error {
+	return strokeCoordSeqs(gc, [][][]float64{lineCoords}, style, scale)
+}
+
+func DrawPolygon(gc draw2d.GraphicContext, g *geos.Geom, fillColor color.Color, strokeColor color.Color, strokeWidth float64, scale func(x, y float64) (float64, float64)) error {
+	style := Style{
+		FillColor:   fillColor,
+		StrokeColor: strokeColor,
+		StrokeWidth: strokeWidth,
+	}
+
+	return DrawPolygonWithStyle(gc, g, style, scale)
+}
+
+func DrawPolygonWithStyle(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) error {
+	if g.TypeID() == geos.TypeIDMultiPolygon {
+		return DrawMultiPolygonWithStyle(gc, g, style, scale)
+	}
+
+	gc.SetFillColor(style.fill())
+	gc.SetStrokeColor(style.stroke())
+	gc.SetLineWidth(style.StrokeWidth)
+	style.applyLine(gc)
+
+	gc.SetFillRule(style.FillRule)
+
+	// all rings go into a single path so holes are left unpainted by the fill rule
+	for _, rings := range GetParts(g) {
+		for i, ring := range rings {
+			ring := ring
+			cs := &ring
+			if style.FillRule == draw2d.FillRuleWinding {
+				// non-zero winding needs holes to wind against the exterior
+				cs = orientRing(cs, i == 0)
+			}
+
+			err := lineCoordSeq(gc, cs, scale)
+			if err != nil {
+				return (err)
+			}
+			gc.Close()
+		}
+	}
+
+	if style.FillColor != nil && !style.Pattern.isSet() {
+		fillStrokePath(gc, style)
+		return nil
+	}
+
+	// the outline goes over the pattern so is stroked on its own
+	path := gc.GetPath()
+	if style.FillColor != nil {
+		gc.Fill()
+	} else {
+		gc.BeginPath()
 	}
-	gc.Stroke()
+
+	if style.Pattern.isSet() {
+		drawPattern(gc, g, style, scale)
+	}
+
+	strokePath(gc, style, &path)
 
 	return nil
 }
 
-func DrawCoordLine(gc draw2d.GraphicContext, lineCoords [][]float64, lineWidth float64, fillColor color.Color, strokeWidth float64, strokeColor color.Color, scale func(x, y float64) (float64, float64)) error {
-	if lineWidth == 0.0 {
-		return errors.New("line width cannot be zero")
+func DrawMultiPolygon(gc draw2d.GraphicContext, g *geos.Geom, fillColor color.Color, strokeColor color.Color, strokeWidth float64, scale func(x, y float64) (float64, float64)) error {
+	style := Style{
+		FillColor:   fillColor,
+		StrokeColor: strokeColor,
+		StrokeWidth: strokeWidth,
 	}
 
-	// first line is for the stroke (beneath)
-	gc.SetStrokeColor(strokeColor)
-	gc.SetLineWidth(lineWidth + strokeWidth)
+	return DrawMultiPolygonWithStyle(gc, g, style, scale)
+}
 
-	err := lineCoordSeq(gc, &lineCoords, scale)
+func DrawMultiPolygonWithStyle(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) error {
+	// each member is drawn with its own holes
+	for i := 0; i < g.NumGeometries(); i++ {
+		p := g.Geometry(i)
+		if p.IsEmpty() {
+			continue
+		}
+
+		err := DrawPolygonWithStyle(gc, p, style, scale)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func DrawGeometry(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) error {
+	if g.IsEmpty() {
+		return nil
+	}
+
+	switch g.TypeID() {
+	case geos.TypeIDPoint:
+		return DrawPointWithStyle(gc, g, style, scale)
+
+	case geos.TypeIDLineString, geos.TypeIDLinearRing:
+		return DrawLineWithStyle(gc, g, style, scale)
+
+	case geos.TypeIDPolygon:
+		return DrawPolygonWithStyle(gc, g, style, scale)
+
+	case geos.TypeIDMultiPoint:
+		return DrawMultiPointWithStyle(gc, g, style, scale)
+
+	case geos.TypeIDMultiPolygon:
+		return DrawMultiPolygonWithStyle(gc, g, style, scale)
+
+	case geos.TypeIDMultiLineString, geos.TypeIDGeometryCollection:
+		// each part is drawn on its own so multi part lines are not joined up
+		for i := 0; i < g.NumGeometries(); i++ {
+			err := DrawGeometry(gc, g.Geometry(i), style, scale)
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("geom type not supported %v", g.TypeID())
+	}
+
+	return nil
+}
+
+func DrawDot(gc draw2d.GraphicContext, radius, x, y float64) error {
+	gc.MoveTo(x, y)
+	gc.ArcTo(x, y, radius, radius, 0, 2*math.Pi)
+	gc.Fill()
+
+	return nil
+}
+
+func strokeCoordSeqs(gc draw2d.GraphicContext, lines [][][]float64, style Style, scale func(x, y float64) (float64, float64)) error {
+	// first line is for the stroke (beneath)
+	err := strokeCasing(gc, lines, style, scale)
 	if err != nil {
 		return (err)
 	}
-	gc.Stroke()
 
 	// the actual line
-	gc.SetStrokeColor(fillColor)
-	gc.SetLineWidth(lineWidth)
+	return strokeLine(gc, lines, style, scale)
+}
+
+func strokeCasing(gc draw2d.GraphicContext, lines [][][]float64, style Style, scale func(x, y float64) (float64, float64)) error {
+	if style.LineWidth == 0.0 {
+		return errors.New("line width cannot be zero")
+	}
 
-	err = lineCoordSeq(gc, &lineCoords, scale)
+	style.applyLine(gc)
+
+	gc.SetStrokeColor(style.stroke())
+	gc.SetLineWidth(style.LineWidth + style.StrokeWidth)
+	if len(style.Dash) > 0 {
+		gc.SetLineDash(style.casingDash())
+	}
+
+	err := linesCoordSeq(gc, lines, scale)
 	if err != nil {
 		return (err)
 	}
-	gc.Stroke()
+	strokePath(gc, style)
 
 	return nil
 }
 
-func DrawPolygon(gc draw2d.GraphicContext, g *geos.Geom, fillColor color.Color, strokeColor color.Color, strokeWidth float64, scale func(x, y float64) (float64, float64)) error {
-	gc.SetFillColor(fillColor)
-	gc.SetStrokeColor(strokeColor)
-	gc.SetLineWidth(strokeWidth)
+func strokeLine(gc draw2d.GraphicContext, lines [][][]float64, style Style, scale func(x, y float64) (float64, float64)) error {
+	if style.LineWidth == 0.0 {
+		return errors.New("line width cannot be zero")
+	}
+
+	style.applyLine(gc)
 
-	// exterior ring
-	cs := GetPoints(g.ExteriorRing())
+	gc.SetStrokeColor(style.fill())
+	gc.SetLineWidth(style.LineWidth)
 
-	err := lineCoordSeq(gc, cs, scale)
+	err := linesCoordSeq(gc, lines, scale)
 	if err != nil {
 		return (err)
 	}
-	gc.FillStroke()
-	// interior rings...
+	strokePath(gc, style)
 
-	n := g.NumInteriorRings()
-	for i := 0; i < n; i++ {
-		err = lineCoordSeq(gc, GetPoints(g.InteriorRing(i)), scale)
+	return nil
+}
+
+func linesCoordSeq(gc draw2d.GraphicContext, lines [][][]float64, scale func(x, y float64) (float64, float64)) error {
+	for _, line := range lines {
+		line := line
+		err := lineCoordSeq(gc, &line, scale)
 		if err != nil {
 			return (err)
 		}
-		gc.SetFillColor(color.White)
-		gc.FillStroke()
 	}
 
 	return nil
 }
 
-func DrawDot(gc *draw2dimg.GraphicContext, radius, x, y float64) error {
-	gc.MoveTo(x, y)
-	gc.ArcTo(x, y, radius, radius, 0, 2*math.Pi)
-	gc.Fill()
-
-	return nil
-}
-
 func lineCoordSeq(gc draw2d.GraphicContext, cs *[][]float64, scale func(x, y float64) (float64, float64)) error {
 	if cs == nil {
 		return errors.New("coord seq cannot be nil")
 	}
 
 	csd := *cs
+	if len(csd) == 0 {
+		return errors.New("coord seq cannot be empty")
+	}
 
 	gc.MoveTo(scale(csd[0][0], csd[0][1]))
 
@@ -168,3 +379,30 @@ func lineCoordSeq(gc draw2d.GraphicContext, cs *[][]float64, scale func(x, y flo
 
 	return nil
 }
+
+func orientRing(cs *[][]float64, ccw bool) *[][]float64 {
+	csd := *cs
+
+	if (signedArea(csd) > 0) == ccw {
+		return cs
+	}
+
+	r := make([][]float64, len(csd))
+	for i, c := range csd {
+		r[len(csd)-1-i] = c
+	}
+
+	return &r
+}
+
+func signedArea(csd [][]float64) float64 {
+	var a float64
+
+	n := len(csd)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		a += csd[i][0]*csd[j][1] - csd[j][0]*csd[i][1]
+	}
+
+	return a / 2
+}
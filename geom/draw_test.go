@@ -0,0 +1,463 @@
+package geom
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func TestDrawGeometry(t *testing.T) {
+	type sample struct {
+		x, y     int
+		expected color.RGBA
+	}
+
+	tests := map[string]struct {
+		wkt     string
+		samples []sample
+	}{
+		"point": {
+			wkt: "POINT (30 30)",
+			samples: []sample{
+				{300, 300, blue},
+				{320, 320, white},
+			},
+		},
+		"multipoint": {
+			wkt: "MULTIPOINT ((10 10), (30 30), (50 50))",
+			samples: []sample{
+				{100, 100, blue},
+				{300, 300, blue},
+				{500, 500, blue},
+				{200, 200, white},
+			},
+		},
+		"multilinestring": {
+			wkt: "MULTILINESTRING ((5 5, 25 25, 5 45), (35 5, 55 25, 35 45))",
+			samples: []sample{
+				{150, 150, blue},
+				{450, 150, blue},
+				{300, 250, white},
+			},
+		},
+		"multipolygon": {
+			wkt: "MULTIPOLYGON (((5 5, 25 5, 25 25, 5 25, 5 5)), ((35 35, 55 35, 55 55, 35 55, 35 35)))",
+			samples: []sample{
+				{150, 150, blue},
+				{450, 450, blue},
+				{300, 300, white},
+			},
+		},
+		"collection": {
+			wkt: "GEOMETRYCOLLECTION (POINT (10 50), LINESTRING (5 5, 55 5), GEOMETRYCOLLECTION (POLYGON ((20 20, 40 20, 40 40, 20 40, 20 20)), MULTIPOINT ((50 50), (50 10))))",
+			samples: []sample{
+				{100, 500, blue},
+				{300, 50, blue},
+				{300, 300, blue},
+				{500, 500, blue},
+				{500, 100, blue},
+				{150, 300, white},
+			},
+		},
+		"empty": {
+			wkt: "GEOMETRYCOLLECTION EMPTY",
+			samples: []sample{
+				{300, 300, white},
+			},
+		},
+	}
+
+	scale := func(x, y float64) (float64, float64) {
+		return 10 * x, 10 * y
+	}
+
+	style := Style{
+		FillColor:   blue,
+		StrokeColor: black,
+		StrokeWidth: 1,
+		LineWidth:   4,
+		PointRadius: 5,
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 600, 600))
+		draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		gc.SetDPI(72)
+
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = DrawGeometry(gc, g, style, scale)
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		for _, s := range tt.samples {
+			if actual := m.RGBAAt(s.x, s.y); actual != s.expected {
+				t.Errorf("%v: at %v,%v Expected [%+v]\nGot [%+v]", tname, s.x, s.y, s.expected, actual)
+			}
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/draw_geometry_%v.png", tname), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDrawPolygonHoles(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+
+	tests := map[string]struct {
+		wkt      string
+		fillRule draw2d.FillRule
+	}{
+		"evenodd": {
+			wkt:      "POLYGON ((0 0, 60 0, 60 60, 0 60, 0 0), (20 20, 40 20, 40 40, 20 40, 20 20))",
+			fillRule: draw2d.FillRuleEvenOdd,
+		},
+		"winding": {
+			// the hole is wound the same way as the exterior
+			wkt:      "POLYGON ((0 0, 60 0, 60 60, 0 60, 0 0), (20 20, 40 20, 40 40, 20 40, 20 20))",
+			fillRule: draw2d.FillRuleWinding,
+		},
+	}
+
+	scale := func(x, y float64) (float64, float64) {
+		return 10 * x, 10 * y
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 600, 600))
+		draw.Draw(m, m.Bounds(), &image.Uniform{red}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		gc.SetDPI(72)
+
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		style := Style{
+			FillColor:   blue,
+			StrokeColor: black,
+			StrokeWidth: 1,
+			FillRule:    tt.fillRule,
+		}
+
+		err = DrawPolygonWithStyle(gc, g, style, scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := m.RGBAAt(300, 300); actual != red {
+			t.Errorf("%v: hole Expected [%+v]\nGot [%+v]", tname, red, actual)
+		}
+
+		if actual := m.RGBAAt(100, 100); actual != blue {
+			t.Errorf("%v: body Expected [%+v]\nGot [%+v]", tname, blue, actual)
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/polygon_holes_%v.png", tname), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDrawMultiPolygon(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 600, 600))
+	draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+	gc := draw2dimg.NewGraphicContext(m)
+
+	gc.SetDPI(72)
+
+	scale := func(x, y float64) (float64, float64) {
+		return 10 * x, 10 * y
+	}
+
+	g, err := gctx.NewGeomFromWKT("MULTIPOLYGON (((0 0, 20 0, 20 20, 0 20, 0 0)), ((30 30, 60 30, 60 60, 30 60, 30 30), (40 40, 50 40, 50 50, 40 50, 40 40)))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DrawMultiPolygon(gc, g, blue, black, 1, scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		"first":   {100, 100, blue},
+		"second":  {350, 350, blue},
+		"hole":    {450, 450, white},
+		"between": {250, 250, white},
+	}
+
+	for tname, tt := range tests {
+		if actual := m.RGBAAt(tt.x, tt.y); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+
+	err = savePNG("test-output/multipolygon.png", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDrawMultiPoint(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 600, 600))
+	draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+	gc := draw2dimg.NewGraphicContext(m)
+
+	gc.SetDPI(72)
+
+	scale := func(x, y float64) (float64, float64) {
+		return 10 * x, 10 * y
+	}
+
+	g, err := gctx.NewGeomFromWKT("MULTIPOINT ((10 10), (30 30), (50 10))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	style := Style{
+		FillColor:   blue,
+		StrokeColor: black,
+		StrokeWidth: 1,
+		Marker:      Marker{Shape: MarkerSquare, Size: 40},
+	}
+
+	err = DrawPointWithStyle(gc, g, style, scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		"first":   {100, 100, blue},
+		"second":  {300, 300, blue},
+		"third":   {500, 100, blue},
+		"corner":  {115, 115, blue},
+		"between": {200, 200, white},
+	}
+
+	for tname, tt := range tests {
+		if actual := m.RGBAAt(tt.x, tt.y); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+
+	err = savePNG("test-output/multipoint.png", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDrawMultiLineString(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 600, 600))
+	draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+	gc := draw2dimg.NewGraphicContext(m)
+
+	gc.SetDPI(72)
+
+	scale := func(x, y float64) (float64, float64) {
+		return 10 * x, 10 * y
+	}
+
+	g, err := gctx.NewGeomFromWKT("MULTILINESTRING ((10 10, 10 50), (50 50, 50 10))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DrawLine(gc, g, 4, blue, 1, black, scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := m.RGBAAt(100, 300); actual != blue {
+		t.Errorf("first part: Expected [%+v]\nGot [%+v]", blue, actual)
+	}
+
+	// nothing should join the end of the first part to the start of the second
+	if actual := m.RGBAAt(300, 500); actual != white {
+		t.Errorf("gap: Expected [%+v]\nGot [%+v]", white, actual)
+	}
+
+	err = savePNG("test-output/multilinestring.png", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDrawDashedLine(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+
+	type sample struct {
+		x        int
+		expected color.RGBA
+	}
+
+	tests := map[string]struct {
+		style   Style
+		samples []sample
+	}{
+		"dashed": {
+			style: Style{LineWidth: 4, Dash: []float64{10, 10}},
+			samples: []sample{
+				{5, blue},
+				{15, red},
+				{25, blue},
+			},
+		},
+		"offset": {
+			style: Style{LineWidth: 4, Dash: []float64{10, 10}, DashOffset: 5},
+			samples: []sample{
+				{2, blue},
+				{10, red},
+				{20, blue},
+			},
+		},
+		"cased": {
+			style: Style{LineWidth: 4, StrokeWidth: 2, Dash: []float64{10, 10}, LineCap: LineCapButt},
+			samples: []sample{
+				{5, blue},
+				{10, black},
+				{15, red},
+				{19, black},
+				{25, blue},
+			},
+		},
+		"cased no gap": {
+			style: Style{LineWidth: 4, StrokeWidth: 4, Dash: []float64{10, 2}, LineCap: LineCapButt},
+			samples: []sample{
+				{5, blue},
+				{11, black},
+			},
+		},
+		"dotted": {
+			style: Style{LineWidth: 4, Dash: Dotted(4), LineCap: LineCapButt},
+			samples: []sample{
+				{2, blue},
+				{6, red},
+				{10, blue},
+			},
+		},
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 100, 20))
+		draw.Draw(m, m.Bounds(), &image.Uniform{red}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		style := tt.style
+		style.FillColor = blue
+		style.StrokeColor = black
+
+		err := DrawCoordLineWithStyle(gc, [][]float64{{0, 10}, {100, 10}}, style, noscale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, s := range tt.samples {
+			if actual := m.RGBAAt(s.x, 10); actual != s.expected {
+				t.Errorf("%v: at %v Expected [%+v]\nGot [%+v]", tname, s.x, s.expected, actual)
+			}
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/dashed_line_%v.png", strings.ReplaceAll(tname, " ", "_")), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDrawLineCapsJoins(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+
+	type sample struct {
+		x, y     int
+		expected color.RGBA
+	}
+
+	tests := map[string]struct {
+		style   Style
+		samples []sample
+	}{
+		"butt miter": {
+			style: Style{LineCap: LineCapButt, LineJoin: LineJoinMiter},
+			samples: []sample{
+				{15, 20, red},
+				{88, 12, blue},
+			},
+		},
+		"square bevel": {
+			style: Style{LineCap: LineCapSquare, LineJoin: LineJoinBevel},
+			samples: []sample{
+				{15, 20, blue},
+				{12, 12, blue},
+				{86, 14, red},
+				{88, 12, red},
+			},
+		},
+		"round": {
+			style: Style{LineCap: LineCapRound, LineJoin: LineJoinRound},
+			samples: []sample{
+				{15, 20, blue},
+				{11, 11, red},
+				{86, 14, blue},
+				{88, 12, red},
+			},
+		},
+		"miter limit": {
+			style: Style{LineCap: LineCapButt, LineJoin: LineJoinMiter, MiterLimit: 1.2},
+			samples: []sample{
+				{86, 14, red},
+				{88, 12, red},
+			},
+		},
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(m, m.Bounds(), &image.Uniform{red}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		style := tt.style
+		style.LineWidth = 20
+		style.FillColor = blue
+		style.StrokeColor = blue
+
+		err := DrawCoordLineWithStyle(gc, [][]float64{{20, 20}, {80, 20}, {80, 80}}, style, noscale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, s := range tt.samples {
+			if actual := m.RGBAAt(s.x, s.y); actual != s.expected {
+				t.Errorf("%v: at %v,%v Expected [%+v]\nGot [%+v]", tname, s.x, s.y, s.expected, actual)
+			}
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/caps_joins_%v.png", strings.ReplaceAll(tname, " ", "_")), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package geom
 
 import (
+	"fmt"
+
 	geos "github.com/twpayne/go-geos"
 )
 
@@ -24,19 +26,27 @@ func (e Envelope) Py(y float64) float64 {
 	return (y - e.Min[1]) / e.Dy()
 }
 
+func (e Envelope) InvPx(px float64) float64 {
+	return e.Min[0] + px*e.Dx()
+}
+
+func (e Envelope) InvPy(py float64) float64 {
+	return e.Min[1] + py*e.Dy()
+}
+
 func ToEnvelope(g *geos.Geom) (Envelope, error) {
-	l, err := ToLineString(g)
-	if err != nil {
-		return Envelope{}, err
+	cs := exteriorCoords(g)
+	if len(cs) < 5 {
+		return Envelope{}, fmt.Errorf("envelope needs at least 5 points, got %v", len(cs))
 	}
 
-	bl := l.Point(4)
-	tr := l.Point(2)
+	bl := cs[4]
+	tr := cs[2]
 
-	minX := bl.X()
-	maxX := tr.X()
-	minY := bl.Y()
-	maxY := tr.Y()
+	minX := bl[0]
+	maxX := tr[0]
+	minY := bl[1]
+	maxY := tr[1]
 
 	return Envelope{
 		[]float64{
@@ -0,0 +1,64 @@
+package geom
+
+import (
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// OutlineFace is a face that can also give the outline of its glyphs.
+type OutlineFace interface {
+	font.Face
+	GlyphOutline(char rune) (*draw2d.Path, bool)
+}
+
+// NewOutlineFace returns a face for f at size pixels, the outlines match its glyph masks.
+func NewOutlineFace(f *truetype.Font, size float64) OutlineFace {
+	return &trueTypeFace{
+		Face:  truetype.NewFace(f, &truetype.Options{Size: size}),
+		font:  f,
+		scale: fixed.Int26_6(0.5 + size*64),
+	}
+}
+
+type trueTypeFace struct {
+	font.Face
+	font  *truetype.Font
+	scale fixed.Int26_6
+	buf   truetype.GlyphBuf
+}
+
+// GlyphOutline returns the outline of char with its baseline starting at the origin.
+func (f *trueTypeFace) GlyphOutline(char rune) (*draw2d.Path, bool) {
+	i := f.font.Index(char)
+	if i == 0 {
+		return nil, false
+	}
+
+	err := f.buf.Load(f.font, f.scale, i, font.HintingNone)
+	if err != nil {
+		return nil, false
+	}
+
+	path := new(draw2d.Path)
+
+	start := 0
+	for _, end := range f.buf.Ends {
+		draw2dimg.DrawContour(path, f.buf.Points[start:end], 0, 0)
+		start = end
+	}
+
+	return path, true
+}
+
+// glyphOutline returns the outline of char when f has one.
+func glyphOutline(f font.Face, char rune) (*draw2d.Path, bool) {
+	o, ok := f.(OutlineFace)
+	if !ok {
+		return nil, false
+	}
+
+	return o.GlyphOutline(char)
+}
@@ -1,23 +1,47 @@
 package geom
 
 import (
+	"errors"
 	"fmt"
+	"math"
 
 	geos "github.com/twpayne/go-geos"
 )
 
 var gctx = geos.NewContext()
 
-var lineStringEmpty, _ = gctx.NewGeomFromWKT("LINESTRING EMPTY")
-var multiLineStringEmpty, _ = gctx.NewGeomFromWKT("MULTILINESTRING EMPTY")
-var polygonEmpty, _ = gctx.NewGeomFromWKT("POLYGON EMPTY")
-
 func SimplifyGeom(g *geos.Geom, lvl float64) *geos.Geom {
 	return g.Simplify(lvl)
 }
 
-//nolint:exhaustive
+// CenterStrategy is how the centre of a polygon, line or multipoint is found, CenterDefault uses
+// the pole of inaccessibility for polygons and the bounds for lines and multipoints.
+type CenterStrategy int
+
+const (
+	CenterDefault CenterStrategy = iota
+	CenterBounds
+	CenterCentroid
+	CenterPointOnSurface
+	CenterPoleOfInaccessibility
+	CenterAlongLine
+)
+
+// CenterOptions choose how GetGeometryCenterWithOptions finds a centre, Precision is in pixels
+// and is only used for the pole of inaccessibility where zero means to within a pixel,
+// Fraction is how far along a line CenterAlongLine goes where nil means half way.
+type CenterOptions struct {
+	Strategy  CenterStrategy
+	Precision float64
+	Fraction  *float64
+}
+
 func GetGeometryCenter(g *geos.Geom, scale func(x, y float64) (float64, float64)) ([]float64, error) {
+	return GetGeometryCenterWithOptions(g, CenterOptions{}, scale)
+}
+
+//nolint:exhaustive
+func GetGeometryCenterWithOptions(g *geos.Geom, opts CenterOptions, scale func(x, y float64) (float64, float64)) ([]float64, error) {
 	var c []float64
 	var err error
 
@@ -30,7 +54,21 @@ func GetGeometryCenter(g *geos.Geom, scale func(x, y float64) (float64, float64)
 			y,
 		}, nil
 
+	case geos.TypeIDMultiPoint:
+		return multiPointCenter(g, opts, scale)
+
 	case geos.TypeIDMultiLineString, geos.TypeIDLineString:
+		if opts.Strategy == CenterAlongLine {
+			fraction := 0.5
+			if opts.Fraction != nil {
+				fraction = *opts.Fraction
+			}
+
+			c, _, err = LineAnchor(g, fraction, scale)
+
+			return c, err
+		}
+
 		g, err = ScaleLine(g, scale)
 		if err != nil {
 			return []float64{}, err
@@ -38,6 +76,9 @@ func GetGeometryCenter(g *geos.Geom, scale func(x, y float64) (float64, float64)
 
 		c = CenterFromGeometry(g)
 
+	case geos.TypeIDPolygon, geos.TypeIDMultiPolygon:
+		return polygonCenter(g, opts, scale)
+
 	default:
 		return c, fmt.Errorf("geom type not supported %v", g.TypeID())
 	}
@@ -45,6 +86,128 @@ func GetGeometryCenter(g *geos.Geom, scale func(x, y float64) (float64, float64)
 	return c, nil
 }
 
+func polygonCenter(g *geos.Geom, opts CenterOptions, scale func(x, y float64) (float64, float64)) ([]float64, error) {
+	if g.IsEmpty() {
+		return []float64{}, errors.New("polygon cannot be empty")
+	}
+
+	var p *geos.Geom
+
+	switch opts.Strategy {
+	case CenterCentroid:
+		p = g.Centroid()
+
+	case CenterPointOnSurface:
+		p = g.PointOnSurface()
+
+	case CenterBounds:
+		sg, err := scalePolygon(g, scale)
+		if err != nil {
+			return []float64{}, err
+		}
+
+		return CenterFromGeometry(sg), nil
+
+	case CenterDefault, CenterPoleOfInaccessibility:
+		sg, err := scalePolygon(g, scale)
+		if err != nil {
+			return []float64{}, err
+		}
+
+		precision := opts.Precision
+		if precision <= 0 {
+			precision = 1
+		}
+
+		// the circle comes back as a line from its centre to the nearest edge
+		cs := sg.MaximumInscribedCircle(precision).CoordSeq().ToCoords()
+
+		return cs[0], nil
+
+	default:
+		return []float64{}, fmt.Errorf("center strategy not supported %v", opts.Strategy)
+	}
+
+	x, y := scale(p.X(), p.Y())
+
+	return []float64{
+		x,
+		y,
+	}, nil
+}
+
+// LineAnchor returns the point fraction of the way along a line and the angle of the line there
+// in degrees, both in pixels. The angle follows the direction of the line so may need turning
+// to keep text upright and multi part lines use their longest part.
+func LineAnchor(g *geos.Geom, fraction float64, scale func(x, y float64) (float64, float64)) ([]float64, float64, error) {
+	//nolint:exhaustive
+	switch g.TypeID() {
+	case geos.TypeIDLineString, geos.TypeIDLinearRing, geos.TypeIDMultiLineString:
+	default:
+		return []float64{}, 0, fmt.Errorf("geom type not supported %v", g.TypeID())
+	}
+
+	if fraction < 0 || fraction > 1 {
+		return []float64{}, 0, fmt.Errorf("fraction must be between 0 and 1, got %v", fraction)
+	}
+
+	var line [][]float64
+	length := -1.0
+
+	for _, part := range GetParts(g) {
+		cs := transformCoords(part[0], scale)
+		if l := lineLength(cs); l > length {
+			line = cs
+			length = l
+		}
+	}
+
+	if len(line) < 2 {
+		return []float64{}, 0, fmt.Errorf("line needs at least 2 points, got %v", len(line))
+	}
+
+	x, y, angle := pointAlongLine(line, length*fraction)
+
+	return []float64{x, y}, angle, nil
+}
+
+// multiPointCenter is the middle of the bounds of the points, or their mean with CenterCentroid.
+func multiPointCenter(g *geos.Geom, opts CenterOptions, scale func(x, y float64) (float64, float64)) ([]float64, error) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+	var sumX, sumY float64
+	var count int
+
+	for i := 0; i < g.NumGeometries(); i++ {
+		p := g.Geometry(i)
+		if p.IsEmpty() {
+			continue
+		}
+
+		x, y := scale(p.X(), p.Y())
+
+		minX = math.Min(minX, x)
+		minY = math.Min(minY, y)
+		maxX = math.Max(maxX, x)
+		maxY = math.Max(maxY, y)
+
+		sumX += x
+		sumY += y
+		count++
+	}
+
+	if count == 0 {
+		return []float64{}, errors.New("multipoint cannot be empty")
+	}
+
+	if opts.Strategy == CenterCentroid {
+		return []float64{sumX / float64(count), sumY / float64(count)}, nil
+	}
+
+	return []float64{minX + (maxX-minX)/2, minY + (maxY-minY)/2}, nil
+}
+
 func CenterFromGeometry(g *geos.Geom) []float64 {
 	var Xmin, Ymin, Xmax, Ymax float64
 
@@ -69,75 +232,100 @@ func noscale(x, y float64) (float64, float64) {
 }
 
 func ScaleLine(g *geos.Geom, scale func(x, y float64) (float64, float64)) (*geos.Geom, error) {
-	r := "MULTILINESTRING"
+	var lines []*geos.Geom
 
-	t, err := transform(r, g, true, scale)
-	if err != nil {
-		return multiLineStringEmpty, err
+	for _, part := range GetParts(g) {
+		for _, ring := range part {
+			cs := transformCoords(ring, scale)
+			if len(cs) < 2 {
+				return gctx.NewEmptyCollection(geos.TypeIDMultiLineString), fmt.Errorf("transform: line needs at least 2 points, got %v", len(cs))
+			}
+
+			lines = append(lines, gctx.NewLineString(cs))
+		}
 	}
 
-	return t, nil
+	return gctx.NewCollection(geos.TypeIDMultiLineString, lines), nil
 }
 
-func ToLineString(g *geos.Geom) (*geos.Geom, error) {
-	r := "LINESTRING"
+// scalePolygon moves every ring of g into pixels, keeping holes.
+func scalePolygon(g *geos.Geom, scale func(x, y float64) (float64, float64)) (*geos.Geom, error) {
+	var polys []*geos.Geom
 
-	t, err := transform(r, g, false, noscale)
-	if err != nil {
-		return lineStringEmpty, err
+	for _, part := range GetParts(g) {
+		var rings [][][]float64
+
+		for _, ring := range part {
+			cs := transformCoords(ring, scale)
+			if len(cs) < 4 {
+				return gctx.NewEmptyPolygon(), fmt.Errorf("transform: ring needs at least 4 points, got %v", len(cs))
+			}
+
+			rings = append(rings, cs)
+		}
+
+		polys = append(polys, gctx.NewPolygon(rings))
 	}
 
-	return t, nil
+	if len(polys) == 1 {
+		return polys[0], nil
+	}
+
+	return gctx.NewCollection(geos.TypeIDMultiPolygon, polys), nil
 }
 
-func ToPolygon(g *geos.Geom) (*geos.Geom, error) {
-	r := "POLYGON"
+func ToLineString(g *geos.Geom) (*geos.Geom, error) {
+	cs := transformCoords(exteriorCoords(g), noscale)
 
-	t, err := transform(r, g, false, noscale)
-	if err != nil {
-		return polygonEmpty, err
+	switch len(cs) {
+	case 0:
+		return gctx.NewEmptyLineString(), nil
+	case 1:
+		return gctx.NewEmptyLineString(), fmt.Errorf("transform: line needs at least 2 points, got %v", len(cs))
 	}
 
-	return t, nil
+	return gctx.NewLineString(cs), nil
 }
 
-func transform(gType string, g *geos.Geom, multi bool, scale func(x, y float64) (float64, float64)) (*geos.Geom, error) {
-	s := transformToPoints(gType, g, multi, scale)
-	if s == "" {
-		return lineStringEmpty, nil
+func ToPolygon(g *geos.Geom) (*geos.Geom, error) {
+	cs := transformCoords(exteriorCoords(g), noscale)
+
+	n := len(cs)
+	if n == 0 {
+		return gctx.NewEmptyPolygon(), nil
 	}
 
-	p, err := gctx.NewGeomFromWKT(s)
-	if err != nil {
-		return lineStringEmpty, fmt.Errorf("transform %v: %v", err.Error(), s)
+	if n < 4 {
+		return gctx.NewEmptyPolygon(), fmt.Errorf("transform: ring needs at least 4 points, got %v", n)
+	}
+
+	if cs[0][0] != cs[n-1][0] || cs[0][1] != cs[n-1][1] {
+		return gctx.NewEmptyPolygon(), fmt.Errorf("transform: ring is not closed %v %v", cs[0], cs[n-1])
 	}
 
-	return p, nil
+	return gctx.NewPolygon([][][]float64{cs}), nil
 }
 
-func transformToPoints(r string, g *geos.Geom, multi bool, scale func(x, y float64) (float64, float64)) string {
-	var startSep, endSep string
+// exteriorCoords joins the exterior rings of every part of g into one list.
+func exteriorCoords(g *geos.Geom) [][]float64 {
+	var res [][]float64
 
-	if multi {
-		startSep = "(("
-		endSep = "))"
-	} else {
-		startSep = "("
-		endSep = ")"
+	for _, part := range GetParts(g) {
+		res = append(res, part[0]...)
 	}
-	r = fmt.Sprintf("%v %v", r, startSep)
-	points := GetPoints(g)
-	l := len(*points)
-	for i, p := range *points {
-		x, y := scale(p[0], p[1])
-		r = fmt.Sprintf("%v%v %v", r, x, y)
-		if i < l-1 {
-			r = fmt.Sprintf("%v,", r)
-		}
+
+	return res
+}
+
+func transformCoords(cs [][]float64, scale func(x, y float64) (float64, float64)) [][]float64 {
+	res := make([][]float64, len(cs))
+
+	for i, c := range cs {
+		x, y := scale(c[0], c[1])
+		res[i] = []float64{x, y}
 	}
-	r = fmt.Sprintf("%v%v", r, endSep)
 
-	return r
+	return res
 }
 
 func GetOrd(cs *geos.CoordSeq, fn func(*geos.CoordSeq, int) float64) func(int) float64 {
@@ -176,12 +364,44 @@ func BoundsGeom(xmin, xmax, ymin, ymax float64) (*geos.Geom, error) {
 
 	g, err := gctx.NewGeomFromWKT(wkt)
 	if err != nil {
-		return polygonEmpty, err
+		return gctx.NewEmptyPolygon(), err
 	}
 
 	return g, nil
 }
 
+// GetParts returns the coordinates of g grouped by part and then by ring, polygon parts
+// hold their exterior ring first followed by any interior rings.
+func GetParts(g *geos.Geom) [][][][]float64 {
+	var res [][][][]float64
+
+	if g.IsEmpty() {
+		return res
+	}
+
+	switch g.TypeID() {
+	case geos.TypeIDPoint, geos.TypeIDLineString, geos.TypeIDLinearRing:
+		res = append(res, [][][]float64{
+			g.CoordSeq().ToCoords(),
+		})
+	case geos.TypeIDPolygon:
+		rings := [][][]float64{
+			g.ExteriorRing().CoordSeq().ToCoords(),
+		}
+		for i := 0; i < g.NumInteriorRings(); i++ {
+			rings = append(rings, g.InteriorRing(i).CoordSeq().ToCoords())
+		}
+		res = append(res, rings)
+	case geos.TypeIDMultiPoint, geos.TypeIDMultiLineString, geos.TypeIDMultiPolygon, geos.TypeIDGeometryCollection:
+		for i := 0; i < g.NumGeometries(); i++ {
+			res = append(res, GetParts(g.Geometry(i))...)
+		}
+	}
+
+	return res
+}
+
+// Deprecated: GetPoints joins all parts into one list and drops interior rings, use GetParts.
 func GetPoints(list ...*geos.Geom) *[][]float64 {
 	var res [][]float64
 
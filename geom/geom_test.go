@@ -5,12 +5,17 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dsvg"
+	godraw "github.com/rockwell-uk/go-draw/draw"
+	geos "github.com/twpayne/go-geos"
 )
 
 var (
@@ -157,6 +162,60 @@ func TestGetGeometryCenter(t *testing.T) {
 	}
 }
 
+func TestGetParts(t *testing.T) {
+	tests := map[string]struct {
+		wkt      string
+		expected [][][][]float64
+	}{
+		"Point": {
+			wkt: "POINT (1 2)",
+			expected: [][][][]float64{
+				{{{1, 2}}},
+			},
+		},
+		"MultiLineString": {
+			wkt: "MULTILINESTRING ((0 0, 1 1), (2 2, 3 3))",
+			expected: [][][][]float64{
+				{{{0, 0}, {1, 1}}},
+				{{{2, 2}, {3, 3}}},
+			},
+		},
+		"Polygon": {
+			wkt: "POLYGON ((0 0, 4 0, 4 4, 0 0), (1 1, 2 1, 2 2, 1 1))",
+			expected: [][][][]float64{
+				{
+					{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+					{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+				},
+			},
+		},
+		"GeometryCollection": {
+			wkt: "GEOMETRYCOLLECTION (POINT (1 2), MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0))), LINESTRING EMPTY)",
+			expected: [][][][]float64{
+				{{{1, 2}}},
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			},
+		},
+		"Empty": {
+			wkt:      "MULTIPOLYGON EMPTY",
+			expected: nil,
+		},
+	}
+
+	for tname, tt := range tests {
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := GetParts(g)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+}
+
 func TestBoundary(t *testing.T) {
 	tests := map[string]struct {
 		wkt      string
@@ -208,14 +267,7 @@ func TestDrawInvalidBoundary(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		tileHeight := float64(tt.dim)
-		tileWidth := float64(tt.dim)
-
-		scale := func(x, y float64) (float64, float64) {
-			x = envelope.Px(x) * tileWidth
-			y = tileHeight - (envelope.Py(y) * tileHeight)
-			return x, y
-		}
+		viewport := NewViewport(envelope, float64(tt.dim), float64(tt.dim))
 
 		m := image.NewRGBA(image.Rect(0, 0, tt.dim, tt.dim))
 		draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
@@ -232,7 +284,7 @@ func TestDrawInvalidBoundary(t *testing.T) {
 
 		strokeColour := black
 		fillColour := blue
-		err = DrawLine(gc, g, 1, fillColour, 1, strokeColour, scale)
+		err = DrawLine(gc, g, 1, fillColour, 1, strokeColour, viewport.Scale)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -342,6 +394,25 @@ func TestToEnvelope(t *testing.T) {
 	}
 }
 
+func TestEnvelopeInverse(t *testing.T) {
+	envelope := Envelope{Min: []float64{380596, 413770}, Max: []float64{380656, 413830}}
+
+	tests := map[string][]float64{
+		"min":    {380596, 413770},
+		"max":    {380656, 413830},
+		"inside": {380626.5, 413801.25},
+	}
+
+	for tname, p := range tests {
+		x := envelope.InvPx(envelope.Px(p[0]))
+		y := envelope.InvPy(envelope.Py(p[1]))
+
+		if !closeTo(x, p[0]) || !closeTo(y, p[1]) {
+			t.Errorf("%v: Expected [%v]\nGot [%v %v]", tname, p, x, y)
+		}
+	}
+}
+
 func TestScaleLine(t *testing.T) {
 	scale := func(x, y float64) (float64, float64) {
 		return 10 * x, 10 * y
@@ -395,6 +466,26 @@ func savePNG(fname string, m image.Image) error {
 	return nil
 }
 
+func saveSVG(fname string, s *draw2dsvg.Svg) error {
+	dir, _ := path.Split(fname)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return draw2dsvg.SaveToSvgFile(fname, s)
+}
+
+func saveFile(fname string, b []byte) error {
+	dir, _ := path.Split(fname)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fname, b, 0o600)
+}
+
 func BenchmarkScaleLine(b *testing.B) {
 	wkt := "MULTILINESTRING ((388874 413258.9999997683,388844.99999999994 413290.99999976775,388740.99999999994 413427.9999997701,388659.00000000006 413499.99999976833,388648 413512.9999997685,388583.00000000006 413820.9999997686))"
 	tileHeight := float64(600)
@@ -416,16 +507,332 @@ func BenchmarkScaleLine(b *testing.B) {
 		b.Fatal(err)
 	}
 
+	viewport := NewViewport(envelope, tileWidth, tileHeight)
+
+	for i := 0; i < b.N; i++ {
+		_, err = ScaleLine(g, viewport.Scale)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScaleLine100k(b *testing.B) {
+	g := benchmarkLine(100000)
+
 	scale := func(x, y float64) (float64, float64) {
-		x = envelope.Px(x) * tileWidth
-		y = tileHeight - (envelope.Py(y) * tileHeight)
-		return x, y
+		return 10 * x, 10 * y
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ScaleLine(g, scale)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToLineString100k(b *testing.B) {
+	g := benchmarkLine(100000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ToLineString(g)
+		if err != nil {
+			b.Fatal(err)
+		}
 	}
+}
 
+func BenchmarkToPolygon100k(b *testing.B) {
+	coords, err := godraw.Circle([]float64{0, 0}, 1000, 100000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	coords = append(coords, coords[0])
+
+	g := gctx.NewLineString(coords)
+
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err = ScaleLine(g, scale)
+		_, err := ToPolygon(g)
 		if err != nil {
 			b.Fatal(err)
 		}
 	}
 }
+
+// BenchmarkWKT10k compares building geometries from coordinates against the wkt round trip they
+// replaced, formatting the wkt grows quadratically so 10k vertices are used to keep it quick.
+func BenchmarkWKT10k(b *testing.B) {
+	line := benchmarkLine(10000)
+
+	coords, err := godraw.Circle([]float64{0, 0}, 1000, 10000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ring := gctx.NewLineString(append(coords, coords[0]))
+
+	scale := func(x, y float64) (float64, float64) {
+		return 10 * x, 10 * y
+	}
+
+	tests := map[string]func() (*geos.Geom, error){
+		"ScaleLine": func() (*geos.Geom, error) {
+			return ScaleLine(line, scale)
+		},
+		"ScaleLine WKT": func() (*geos.Geom, error) {
+			return wktTransform("MULTILINESTRING", line, true, scale)
+		},
+		"ToLineString": func() (*geos.Geom, error) {
+			return ToLineString(line)
+		},
+		"ToLineString WKT": func() (*geos.Geom, error) {
+			return wktTransform("LINESTRING", line, false, noscale)
+		},
+		"ToPolygon": func() (*geos.Geom, error) {
+			return ToPolygon(ring)
+		},
+		"ToPolygon WKT": func() (*geos.Geom, error) {
+			return wktTransform("POLYGON", ring, true, noscale)
+		},
+	}
+
+	for tname, fn := range tests {
+		fn := fn
+
+		b.Run(strings.ReplaceAll(tname, " ", "_"), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := fn()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkStroke100k draws a 100k vertex line with its casing, once with the default line
+// settings and once with the round caps and joins that need the outline built by strokePath.
+func BenchmarkStroke100k(b *testing.B) {
+	pts := make([][]float64, 100000)
+	for i := range pts {
+		pts[i] = []float64{float64(i) * 1024 / 100000, 512 + 256*math.Sin(float64(i)/100)}
+	}
+
+	tests := map[string]Style{
+		"default": {},
+		"round":   {LineCap: LineCapRound, LineJoin: LineJoinRound},
+	}
+
+	for tname, style := range tests {
+		style := style
+		style.LineWidth = 2
+		style.FillColor = white
+		style.StrokeWidth = 3
+		style.StrokeColor = black
+
+		b.Run(tname, func(b *testing.B) {
+			m := image.NewRGBA(image.Rect(0, 0, 1024, 1024))
+			gc := draw2dimg.NewGraphicContext(m)
+
+			for i := 0; i < b.N; i++ {
+				err := DrawCoordLineWithStyle(gc, pts, style, noscale)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// wktTransform formats every point into wkt and parses it back, as transform did before
+// geometries were built from coordinates.
+func wktTransform(gType string, g *geos.Geom, multi bool, scale func(x, y float64) (float64, float64)) (*geos.Geom, error) {
+	startSep, endSep := "(", ")"
+	if multi {
+		startSep, endSep = "((", "))"
+	}
+
+	r := fmt.Sprintf("%v %v", gType, startSep)
+	points := GetPoints(g)
+	l := len(*points)
+	for i, p := range *points {
+		x, y := scale(p[0], p[1])
+		r = fmt.Sprintf("%v%v %v", r, x, y)
+		if i < l-1 {
+			r = fmt.Sprintf("%v,", r)
+		}
+	}
+	r = fmt.Sprintf("%v%v", r, endSep)
+
+	return gctx.NewGeomFromWKT(r)
+}
+
+func benchmarkLine(n int) *geos.Geom {
+	coords := make([][]float64, n)
+	for i := range coords {
+		coords[i] = []float64{388874 + float64(i)*0.1, 413258.9999997683 + math.Sin(float64(i))}
+	}
+
+	return gctx.NewLineString(coords)
+}
+
+func TestGetGeometryCenterWithOptions(t *testing.T) {
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{100, 100}}
+	viewport := NewViewport(envelope, 100, 100)
+
+	// an L with its bounding box centre outside of it
+	l := "POLYGON ((0 0, 100 0, 100 20, 20 20, 20 100, 0 100, 0 0))"
+
+	tests := map[string]struct {
+		wkt      string
+		opts     CenterOptions
+		expected []float64
+		inside   bool
+	}{
+		"bounds": {
+			wkt:      l,
+			opts:     CenterOptions{Strategy: CenterBounds},
+			expected: []float64{50, 50},
+		},
+		"centroid": {
+			wkt:      l,
+			opts:     CenterOptions{Strategy: CenterCentroid},
+			expected: []float64{116000.0 / 3600, 100 - 116000.0/3600},
+		},
+		"point on surface": {
+			wkt:    l,
+			opts:   CenterOptions{Strategy: CenterPointOnSurface},
+			inside: true,
+		},
+		"pole": {
+			wkt:    l,
+			opts:   CenterOptions{},
+			inside: true,
+		},
+		"pole precision": {
+			wkt:      "POLYGON ((0 0, 40 0, 40 100, 0 100, 0 0), (10 10, 30 10, 30 60, 10 60, 10 10))",
+			opts:     CenterOptions{Strategy: CenterPoleOfInaccessibility, Precision: 0.01},
+			expected: []float64{20, 20},
+		},
+		"multipolygon": {
+			wkt:      "MULTIPOLYGON (((0 0, 10 0, 10 10, 0 10, 0 0)), ((50 50, 90 50, 90 90, 50 90, 50 50)))",
+			opts:     CenterOptions{},
+			expected: []float64{70, 30},
+		},
+		"multipoint": {
+			wkt:      "MULTIPOINT ((10 10), (30 10), (20 70))",
+			opts:     CenterOptions{},
+			expected: []float64{20, 60},
+		},
+		"multipoint centroid": {
+			wkt:      "MULTIPOINT ((10 10), (30 10), (20 70))",
+			opts:     CenterOptions{Strategy: CenterCentroid},
+			expected: []float64{20, 70},
+		},
+	}
+
+	for tname, tt := range tests {
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := GetGeometryCenterWithOptions(g, tt.opts, viewport.Scale)
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		if tt.expected != nil && (math.Abs(actual[0]-tt.expected[0]) > 0.1 || math.Abs(actual[1]-tt.expected[1]) > 0.1) {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+
+		if tt.inside {
+			x, y := viewport.Inverse(actual[0], actual[1])
+			if !g.Contains(gctx.NewPoint([]float64{x, y})) {
+				t.Errorf("%v: %v is not inside the polygon", tname, actual)
+			}
+		}
+	}
+}
+
+func TestLineAnchor(t *testing.T) {
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{100, 100}}
+	viewport := NewViewport(envelope, 100, 100)
+
+	tests := map[string]struct {
+		wkt      string
+		fraction float64
+		expected []float64
+		angle    float64
+	}{
+		"half way": {
+			wkt:      "LINESTRING (0 50, 100 50)",
+			fraction: 0.5,
+			expected: []float64{50, 50},
+			angle:    0,
+		},
+		"bend": {
+			wkt:      "LINESTRING (0 90, 90 90, 90 0)",
+			fraction: 0.75,
+			expected: []float64{90, 55},
+			angle:    90,
+		},
+		"start": {
+			wkt:      "LINESTRING (100 50, 0 50)",
+			fraction: 0,
+			expected: []float64{100, 50},
+			angle:    180,
+		},
+		"longest part": {
+			wkt:      "MULTILINESTRING ((0 0, 10 0), (20 20, 20 80))",
+			fraction: 0.5,
+			expected: []float64{20, 50},
+			angle:    -90,
+		},
+	}
+
+	for tname, tt := range tests {
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, angle, err := LineAnchor(g, tt.fraction, viewport.Scale)
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		if !closeTo(actual[0], tt.expected[0]) || !closeTo(actual[1], tt.expected[1]) || !closeTo(angle, tt.angle) {
+			t.Errorf("%v: Expected [%+v %v]\nGot [%+v %v]", tname, tt.expected, tt.angle, actual, angle)
+		}
+	}
+
+	g, err := gctx.NewGeomFromWKT("LINESTRING (0 50, 100 50)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := GetGeometryCenterWithOptions(g, CenterOptions{Strategy: CenterAlongLine}, viewport.Scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(c[0], 50) || !closeTo(c[1], 50) {
+		t.Errorf("along line: Expected [[50 50]]\nGot [%+v]", c)
+	}
+
+	start := 0.0
+	c, err = GetGeometryCenterWithOptions(g, CenterOptions{Strategy: CenterAlongLine, Fraction: &start}, viewport.Scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(c[0], 0) || !closeTo(c[1], 50) {
+		t.Errorf("line start: Expected [[0 50]]\nGot [%+v]", c)
+	}
+
+	if _, _, err := LineAnchor(g, 2, viewport.Scale); err == nil {
+		t.Errorf("expected an error for a fraction over 1")
+	}
+}
@@ -0,0 +1,111 @@
+package geom
+
+import (
+	"math"
+
+	geos "github.com/twpayne/go-geos"
+)
+
+// GeometriesAt returns the indexes of the geoms that are drawn within tolerance pixels of pos.
+func GeometriesAt(pos []float64, geoms []*geos.Geom, tolerance float64, scale func(x, y float64) (float64, float64)) []int {
+	var res []int
+
+	for i, g := range geoms {
+		if hitTest(g, pos, tolerance, scale) {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
+
+//nolint:exhaustive
+func hitTest(g *geos.Geom, pos []float64, tolerance float64, scale func(x, y float64) (float64, float64)) bool {
+	if g.IsEmpty() {
+		return false
+	}
+
+	switch g.TypeID() {
+	case geos.TypeIDPoint:
+		x, y := scale(g.X(), g.Y())
+		return math.Hypot(x-pos[0], y-pos[1]) <= tolerance
+
+	case geos.TypeIDLineString, geos.TypeIDLinearRing:
+		return lineDistance(g.CoordSeq().ToCoords(), pos, scale) <= tolerance
+
+	case geos.TypeIDPolygon:
+		rings := GetParts(g)[0]
+
+		inside := false
+		for _, ring := range rings {
+			if lineDistance(ring, pos, scale) <= tolerance {
+				return true
+			}
+			// even-odd, so being inside a hole cancels out being inside the exterior
+			if ringContains(ring, pos, scale) {
+				inside = !inside
+			}
+		}
+
+		return inside
+
+	default:
+		for i := 0; i < g.NumGeometries(); i++ {
+			if hitTest(g.Geometry(i), pos, tolerance, scale) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func lineDistance(cs [][]float64, pos []float64, scale func(x, y float64) (float64, float64)) float64 {
+	d := math.Inf(1)
+
+	if len(cs) == 0 {
+		return d
+	}
+
+	ax, ay := scale(cs[0][0], cs[0][1])
+	d = math.Hypot(ax-pos[0], ay-pos[1])
+
+	for i := 1; i < len(cs); i++ {
+		bx, by := scale(cs[i][0], cs[i][1])
+		d = math.Min(d, segmentDistance(ax, ay, bx, by, pos[0], pos[1]))
+		ax, ay = bx, by
+	}
+
+	return d
+}
+
+func segmentDistance(ax, ay, bx, by, px, py float64) float64 {
+	dx := bx - ax
+	dy := by - ay
+
+	l := dx*dx + dy*dy
+	if l == 0 {
+		return math.Hypot(px-ax, py-ay)
+	}
+
+	t := ((px-ax)*dx + (py-ay)*dy) / l
+	t = math.Max(0, math.Min(1, t))
+
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
+
+func ringContains(cs [][]float64, pos []float64, scale func(x, y float64) (float64, float64)) bool {
+	inside := false
+
+	n := len(cs)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		ix, iy := scale(cs[i][0], cs[i][1])
+		jx, jy := scale(cs[j][0], cs[j][1])
+
+		if (iy > pos[1]) != (jy > pos[1]) && pos[0] < (jx-ix)*(pos[1]-iy)/(jy-iy)+ix {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
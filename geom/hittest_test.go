@@ -0,0 +1,75 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+
+	geos "github.com/twpayne/go-geos"
+)
+
+func TestGeometriesAt(t *testing.T) {
+	wkts := []string{
+		"POINT (10 10)",
+		"LINESTRING (0 50, 60 50)",
+		"POLYGON ((20 20, 60 20, 60 60, 20 60, 20 20), (30 30, 40 30, 40 40, 30 40, 30 30))",
+		"MULTIPOINT ((55 5), (5 55))",
+	}
+
+	geoms := []*geos.Geom{}
+	for _, wkt := range wkts {
+		g, err := gctx.NewGeomFromWKT(wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		geoms = append(geoms, g)
+	}
+
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}}
+	viewport := NewViewport(envelope, 600, 600)
+
+	tests := map[string]struct {
+		pos      []float64
+		expected []int
+	}{
+		"point": {
+			pos:      []float64{102, 497},
+			expected: []int{0},
+		},
+		"line outside polygon": {
+			pos:      []float64{100, 103},
+			expected: []int{1},
+		},
+		"line over polygon": {
+			pos:      []float64{450, 100},
+			expected: []int{1, 2},
+		},
+		"inside polygon": {
+			pos:      []float64{250, 250},
+			expected: []int{2},
+		},
+		"inside hole": {
+			pos:      []float64{350, 250},
+			expected: nil,
+		},
+		"hole edge": {
+			pos:      []float64{300, 250},
+			expected: []int{2},
+		},
+		"multipoint": {
+			pos:      []float64{548, 548},
+			expected: []int{3},
+		},
+		"nothing": {
+			pos:      []float64{550, 450},
+			expected: nil,
+		},
+	}
+
+	for tname, tt := range tests {
+		actual := GeometriesAt(tt.pos, geoms, 5, viewport.Scale)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%v]\nGot [%v]", tname, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package geom
+
+import (
+	"math"
+	"sort"
+
+	"github.com/llgcode/draw2d"
+	geos "github.com/twpayne/go-geos"
+	"golang.org/x/image/font"
+)
+
+// Label is a candidate label, labels with a higher Priority are placed first.
+type Label struct {
+	Text     string
+	Geom     *geos.Geom
+	Priority float64
+}
+
+// PlacedLabel is where a label was placed, Pos is the start of its baseline in pixels.
+type PlacedLabel struct {
+	Label    Label
+	Pos      []float64
+	Rotation float64
+	Box      [][]float64
+}
+
+// Labeler places labels so they do not collide with each other or with any obstacles.
+type Labeler struct {
+	Face    font.Face
+	Width   float64
+	Height  float64
+	Offset  float64
+	Padding float64
+	index   *boxIndex
+}
+
+// lineFractions are the positions along a line that are tried, best first.
+var lineFractions = []float64{0.5, 0.4, 0.6, 0.3, 0.7, 0.2, 0.8}
+
+func NewLabeler(face font.Face, width, height float64) *Labeler {
+	return &Labeler{
+		Face:   face,
+		Width:  width,
+		Height: height,
+		Offset: 4,
+		index:  newBoxIndex(64),
+	}
+}
+
+func (l *Labeler) AddObstacle(minX, minY, maxX, maxY float64) {
+	l.index.insert(rectBox(minX, minY, maxX, maxY))
+}
+
+// Place returns the labels that fit, features are only avoided once added with AddObstacle.
+func (l *Labeler) Place(labels []Label, scale func(x, y float64) (float64, float64)) []PlacedLabel {
+	sorted := make([]Label, len(labels))
+	copy(sorted, labels)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	var res []PlacedLabel
+
+	for _, lbl := range sorted {
+		if lbl.Text == "" {
+			continue
+		}
+
+		for _, c := range l.candidates(lbl, scale) {
+			if !l.fits(c.Box) {
+				continue
+			}
+
+			l.index.insert(newLabelBox(c.Box, l.Padding))
+			res = append(res, c)
+
+			break
+		}
+	}
+
+	return res
+}
+
+// DrawLabels draws placed labels with f and style, f should be the face they were placed with.
+func DrawLabels(gc draw2d.GraphicContext, f font.Face, placed []PlacedLabel, style LabelStyle) error {
+	for _, p := range placed {
+		_, err := DrawStringWithFace(gc, p.Pos, f, p.Rotation, p.Label.Text, style)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Labeler) fits(corners [][]float64) bool {
+	b := newLabelBox(corners, l.Padding)
+
+	if l.Width > 0 && l.Height > 0 {
+		if b.minX < 0 || b.minY < 0 || b.maxX > l.Width || b.maxY > l.Height {
+			return false
+		}
+	}
+
+	return !l.index.collides(b)
+}
+
+func (l *Labeler) measure(text string) (float64, float64, float64) {
+	m := l.Face.Metrics()
+
+	w := float64(font.MeasureString(l.Face, text)) / 64
+	ascent := float64(m.Ascent) / 64
+	descent := float64(m.Descent) / 64
+
+	return w, ascent, descent
+}
+
+func (l *Labeler) candidates(lbl Label, scale func(x, y float64) (float64, float64)) []PlacedLabel {
+	if lbl.Geom == nil || lbl.Geom.IsEmpty() {
+		return nil
+	}
+
+	var res []PlacedLabel
+
+	//nolint:exhaustive
+	switch lbl.Geom.TypeID() {
+	case geos.TypeIDPoint:
+		x, y := scale(lbl.Geom.X(), lbl.Geom.Y())
+		res = l.pointCandidates(lbl, x, y)
+
+	case geos.TypeIDLineString, geos.TypeIDLinearRing:
+		cs := transformCoords(lbl.Geom.CoordSeq().ToCoords(), scale)
+		res = l.lineCandidates(lbl, cs)
+
+	case geos.TypeIDPolygon:
+		res = l.polygonCandidates(lbl, scale)
+
+	default:
+		for i := 0; i < lbl.Geom.NumGeometries(); i++ {
+			part := lbl
+			part.Geom = lbl.Geom.Geometry(i)
+			res = append(res, l.candidates(part, scale)...)
+		}
+	}
+
+	return res
+}
+
+func (l *Labeler) pointCandidates(lbl Label, x, y float64) []PlacedLabel {
+	w, ascent, descent := l.measure(lbl.Text)
+	o := l.Offset
+	mid := y + (ascent-descent)/2
+
+	// right, left, above, below and then the corners
+	positions := [][]float64{
+		{x + o, mid},
+		{x - o - w, mid},
+		{x - w/2, y - o - descent},
+		{x - w/2, y + o + ascent},
+		{x + o, y - o - descent},
+		{x + o, y + o + ascent},
+		{x - o - w, y - o - descent},
+		{x - o - w, y + o + ascent},
+	}
+
+	res := make([]PlacedLabel, 0, len(positions))
+	for _, p := range positions {
+		res = append(res, l.placedAt(lbl, p, 0))
+	}
+
+	return res
+}
+
+func (l *Labeler) lineCandidates(lbl Label, cs [][]float64) []PlacedLabel {
+	w, ascent, descent := l.measure(lbl.Text)
+
+	length := lineLength(cs)
+	if length < w {
+		return nil
+	}
+
+	var res []PlacedLabel
+
+	for _, f := range lineFractions {
+		x, y, angle := pointAlongLine(cs, length*f)
+
+		// keep text upright
+		if angle > 90 {
+			angle -= 180
+		} else if angle < -90 {
+			angle += 180
+		}
+
+		radians := angle * (math.Pi / 180)
+		sin, cos := math.Sincos(radians)
+
+		// centre the text on the point both along and across the line
+		dx := -w / 2
+		dy := (ascent - descent) / 2
+		pos := []float64{
+			x + dx*cos - dy*sin,
+			y + dx*sin + dy*cos,
+		}
+
+		res = append(res, l.placedAt(lbl, pos, angle))
+	}
+
+	return res
+}
+
+func (l *Labeler) polygonCandidates(lbl Label, scale func(x, y float64) (float64, float64)) []PlacedLabel {
+	w, ascent, descent := l.measure(lbl.Text)
+
+	rings := GetParts(lbl.Geom)[0]
+
+	var anchors [][]float64
+
+	b := lbl.Geom.Bounds()
+	anchors = append(anchors, []float64{b.MinX + (b.MaxX-b.MinX)/2, b.MinY + (b.MaxY-b.MinY)/2})
+
+	c := lbl.Geom.Centroid()
+	anchors = append(anchors, []float64{c.X(), c.Y()})
+
+	s := lbl.Geom.PointOnSurface()
+	anchors = append(anchors, []float64{s.X(), s.Y()})
+
+	var res []PlacedLabel
+
+	for _, a := range anchors {
+		x, y := scale(a[0], a[1])
+		p := l.placedAt(lbl, []float64{x - w/2, y + (ascent-descent)/2}, 0)
+
+		// every corner has to be inside the polygon
+		inside := true
+		for _, corner := range p.Box {
+			if !ringsContain(rings, corner, scale) {
+				inside = false
+				break
+			}
+		}
+
+		if inside {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+// placedAt works out the corners of the text drawn from pos at rotation degrees.
+func (l *Labeler) placedAt(lbl Label, pos []float64, rotation float64) PlacedLabel {
+	w, ascent, descent := l.measure(lbl.Text)
+
+	radians := rotation * (math.Pi / 180)
+	sin, cos := math.Sincos(radians)
+
+	local := [][]float64{
+		{0, -ascent},
+		{w, -ascent},
+		{w, descent},
+		{0, descent},
+	}
+
+	box := make([][]float64, len(local))
+	for i, c := range local {
+		box[i] = []float64{
+			pos[0] + c[0]*cos - c[1]*sin,
+			pos[1] + c[0]*sin + c[1]*cos,
+		}
+	}
+
+	return PlacedLabel{
+		Label:    lbl,
+		Pos:      pos,
+		Rotation: rotation,
+		Box:      box,
+	}
+}
+
+func ringsContain(rings [][][]float64, pos []float64, scale func(x, y float64) (float64, float64)) bool {
+	inside := false
+
+	for _, ring := range rings {
+		if ringContains(ring, pos, scale) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+func lineLength(cs [][]float64) float64 {
+	var l float64
+
+	for i := 1; i < len(cs); i++ {
+		l += math.Hypot(cs[i][0]-cs[i-1][0], cs[i][1]-cs[i-1][1])
+	}
+
+	return l
+}
+
+// pointAlongLine returns the point d along cs and the angle of the segment it is on in degrees.
+func pointAlongLine(cs [][]float64, d float64) (float64, float64, float64) {
+	var angle float64
+
+	for i := 1; i < len(cs); i++ {
+		dx := cs[i][0] - cs[i-1][0]
+		dy := cs[i][1] - cs[i-1][1]
+		sl := math.Hypot(dx, dy)
+		if sl == 0 {
+			continue
+		}
+
+		angle = math.Atan2(dy, dx) / (math.Pi / 180)
+
+		if d <= sl {
+			t := d / sl
+			return cs[i-1][0] + t*dx, cs[i-1][1] + t*dy, angle
+		}
+
+		d -= sl
+	}
+
+	n := len(cs) - 1
+
+	return cs[n][0], cs[n][1], angle
+}
@@ -0,0 +1,143 @@
+package geom
+
+import (
+	"testing"
+
+	geos "github.com/twpayne/go-geos"
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestLabelerPlace(t *testing.T) {
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{100, 100}}
+	viewport := NewViewport(envelope, 100, 100)
+
+	geom := func(wkt string) *geos.Geom {
+		g, err := gctx.NewGeomFromWKT(wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	tests := map[string]struct {
+		labels    []Label
+		obstacles [][]float64
+		expected  []string
+	}{
+		"point": {
+			labels:   []Label{{Text: "a", Geom: geom("POINT (50 50)")}},
+			expected: []string{"a"},
+		},
+		"two labels on one point": {
+			labels: []Label{
+				{Text: "a", Geom: geom("POINT (50 50)")},
+				{Text: "b", Geom: geom("POINT (50 50)")},
+			},
+			expected: []string{"a", "b"},
+		},
+		"priority": {
+			labels: []Label{
+				{Text: "low", Geom: geom("LINESTRING (0 50, 100 50)"), Priority: 1},
+				{Text: "high", Geom: geom("LINESTRING (0 50, 100 50)"), Priority: 2},
+				{Text: "none", Geom: geom("LINESTRING (40 50, 60 50)")},
+			},
+			expected: []string{"high", "low"},
+		},
+		"line too short": {
+			labels:   []Label{{Text: "too long", Geom: geom("LINESTRING (45 50, 55 50)")}},
+			expected: nil,
+		},
+		"polygon": {
+			labels:   []Label{{Text: "area", Geom: geom("POLYGON ((20 20, 80 20, 80 80, 20 80, 20 20))")}},
+			expected: []string{"area"},
+		},
+		"polygon too small": {
+			labels:   []Label{{Text: "area", Geom: geom("POLYGON ((45 45, 55 45, 55 55, 45 55, 45 45))")}},
+			expected: nil,
+		},
+		"off the canvas": {
+			labels:   []Label{{Text: "edge", Geom: geom("POINT (100 100)")}},
+			expected: []string{"edge"},
+		},
+		"empty": {
+			labels:   []Label{{Text: "", Geom: geom("POINT (50 50)")}},
+			expected: nil,
+		},
+		"obstacle": {
+			labels:    []Label{{Text: "a", Geom: geom("POINT (50 50)")}},
+			obstacles: [][]float64{{0, 0, 100, 100}},
+			expected:  nil,
+		},
+	}
+
+	for tname, tt := range tests {
+		l := NewLabeler(basicfont.Face7x13, 100, 100)
+		for _, o := range tt.obstacles {
+			l.AddObstacle(o[0], o[1], o[2], o[3])
+		}
+
+		placed := l.Place(tt.labels, viewport.Scale)
+
+		var actual []string
+		for _, p := range placed {
+			actual = append(actual, p.Label.Text)
+
+			for _, c := range p.Box {
+				if c[0] < 0 || c[1] < 0 || c[0] > 100 || c[1] > 100 {
+					t.Errorf("%v: %v placed off the canvas %v", tname, p.Label.Text, p.Box)
+				}
+			}
+		}
+
+		if len(actual) != len(tt.expected) {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != tt.expected[i] {
+				t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+			}
+		}
+
+		for i := 0; i < len(placed); i++ {
+			for j := i + 1; j < len(placed); j++ {
+				if newLabelBox(placed[i].Box, 0).overlaps(newLabelBox(placed[j].Box, 0)) {
+					t.Errorf("%v: %v overlaps %v", tname, placed[i].Label.Text, placed[j].Label.Text)
+				}
+			}
+		}
+	}
+}
+
+func TestLabelerLineRotation(t *testing.T) {
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{100, 100}}
+	viewport := NewViewport(envelope, 100, 100)
+
+	tests := map[string]struct {
+		wkt      string
+		expected float64
+	}{
+		"left to right": {"LINESTRING (10 50, 90 50)", 0},
+		"right to left": {"LINESTRING (90 50, 10 50)", 0},
+		"upwards":       {"LINESTRING (10 10, 90 90)", -45},
+		"downwards":     {"LINESTRING (90 90, 10 10)", -45},
+	}
+
+	for tname, tt := range tests {
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		l := NewLabeler(basicfont.Face7x13, 100, 100)
+		placed := l.Place([]Label{{Text: "road", Geom: g}}, viewport.Scale)
+
+		if len(placed) != 1 {
+			t.Fatalf("%v: expected 1 label, got %v", tname, len(placed))
+		}
+
+		if !closeTo(placed[0].Rotation, tt.expected) {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, placed[0].Rotation)
+		}
+	}
+}
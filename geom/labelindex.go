@@ -0,0 +1,160 @@
+package geom
+
+import (
+	"math"
+)
+
+// labelBox is a possibly rotated rectangle, the bounds are kept for the grid.
+type labelBox struct {
+	corners [][]float64
+	minX    float64
+	minY    float64
+	maxX    float64
+	maxY    float64
+}
+
+// boxIndex is a grid of cells holding the boxes that touch them, unlike an STRtree it can grow.
+type boxIndex struct {
+	size  float64
+	cells map[[2]int][]*labelBox
+}
+
+func newBoxIndex(size float64) *boxIndex {
+	return &boxIndex{
+		size:  size,
+		cells: make(map[[2]int][]*labelBox),
+	}
+}
+
+func rectBox(minX, minY, maxX, maxY float64) *labelBox {
+	return newLabelBox([][]float64{
+		{minX, minY},
+		{maxX, minY},
+		{maxX, maxY},
+		{minX, maxY},
+	}, 0)
+}
+
+// newLabelBox grows the corners outwards by padding along both axes of the box.
+func newLabelBox(corners [][]float64, padding float64) *labelBox {
+	cs := corners
+
+	if padding != 0 {
+		ux, uy := unit(corners[0], corners[1])
+		vx, vy := unit(corners[0], corners[3])
+
+		signs := [][]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+
+		cs = make([][]float64, len(corners))
+		for i, c := range corners {
+			s := signs[i]
+			cs[i] = []float64{
+				c[0] + padding*(s[0]*ux+s[1]*vx),
+				c[1] + padding*(s[0]*uy+s[1]*vy),
+			}
+		}
+	}
+
+	b := &labelBox{
+		corners: cs,
+		minX:    math.Inf(1),
+		minY:    math.Inf(1),
+		maxX:    math.Inf(-1),
+		maxY:    math.Inf(-1),
+	}
+
+	for _, c := range cs {
+		b.minX = math.Min(b.minX, c[0])
+		b.minY = math.Min(b.minY, c[1])
+		b.maxX = math.Max(b.maxX, c[0])
+		b.maxY = math.Max(b.maxY, c[1])
+	}
+
+	return b
+}
+
+func (idx *boxIndex) insert(b *labelBox) {
+	idx.eachCell(b, func(k [2]int) bool {
+		idx.cells[k] = append(idx.cells[k], b)
+		return true
+	})
+}
+
+func (idx *boxIndex) collides(b *labelBox) bool {
+	hit := false
+
+	idx.eachCell(b, func(k [2]int) bool {
+		for _, o := range idx.cells[k] {
+			if b.overlaps(o) {
+				hit = true
+				return false
+			}
+		}
+		return true
+	})
+
+	return hit
+}
+
+// eachCell calls fn with every cell under the bounds of b until it returns false.
+func (idx *boxIndex) eachCell(b *labelBox, fn func(k [2]int) bool) {
+	x0 := int(math.Floor(b.minX / idx.size))
+	y0 := int(math.Floor(b.minY / idx.size))
+	x1 := int(math.Floor(b.maxX / idx.size))
+	y1 := int(math.Floor(b.maxY / idx.size))
+
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			if !fn([2]int{x, y}) {
+				return
+			}
+		}
+	}
+}
+
+// overlaps uses separating axes, boxes that only touch do not overlap.
+func (b *labelBox) overlaps(o *labelBox) bool {
+	if b.maxX <= o.minX || o.maxX <= b.minX || b.maxY <= o.minY || o.maxY <= b.minY {
+		return false
+	}
+
+	for _, box := range []*labelBox{b, o} {
+		for i := 0; i < 2; i++ {
+			ax, ay := unit(box.corners[i], box.corners[i+1])
+
+			bmin, bmax := project(b.corners, ax, ay)
+			omin, omax := project(o.corners, ax, ay)
+
+			if bmax <= omin || omax <= bmin {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func project(cs [][]float64, ax, ay float64) (float64, float64) {
+	lo := math.Inf(1)
+	hi := math.Inf(-1)
+
+	for _, c := range cs {
+		d := c[0]*ax + c[1]*ay
+		lo = math.Min(lo, d)
+		hi = math.Max(hi, d)
+	}
+
+	return lo, hi
+}
+
+func unit(a, b []float64) (float64, float64) {
+	dx := b[0] - a[0]
+	dy := b[1] - a[1]
+
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return 0, 0
+	}
+
+	return dx / l, dy / l
+}
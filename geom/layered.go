@@ -0,0 +1,68 @@
+package geom
+
+import (
+	"sort"
+
+	"github.com/llgcode/draw2d"
+	geos "github.com/twpayne/go-geos"
+)
+
+// LayeredLine is a line drawn by DrawLayeredLines, lines with a higher Priority are drawn over lower ones.
+type LayeredLine struct {
+	Geom     *geos.Geom
+	Style    Style
+	Priority float64
+}
+
+// DrawLayeredLines draws the casings of every line with the same priority before any of their fills
+// so lines that cross or meet join up cleanly.
+func DrawLayeredLines(gc draw2d.GraphicContext, lines []LayeredLine, scale func(x, y float64) (float64, float64)) error {
+	sorted := make([]LayeredLine, 0, len(lines))
+	for _, l := range lines {
+		if l.Geom == nil || l.Geom.IsEmpty() {
+			continue
+		}
+		sorted = append(sorted, l)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	parts := make([][][][]float64, len(sorted))
+	for i, l := range sorted {
+		for _, part := range GetParts(l.Geom) {
+			parts[i] = append(parts[i], part...)
+		}
+	}
+
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].Priority == sorted[start].Priority {
+			end++
+		}
+
+		// a style only sets the line settings it uses so each line starts from the same gc
+		for i := start; i < end; i++ {
+			gc.Save()
+			err := strokeCasing(gc, parts[i], sorted[i].Style, scale)
+			gc.Restore()
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := start; i < end; i++ {
+			gc.Save()
+			err := strokeLine(gc, parts[i], sorted[i].Style, scale)
+			gc.Restore()
+			if err != nil {
+				return err
+			}
+		}
+
+		start = end
+	}
+
+	return nil
+}
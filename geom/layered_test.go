@@ -0,0 +1,82 @@
+package geom
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func TestDrawLayeredLines(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+
+	tests := map[string]struct {
+		priorities []float64
+		expected   color.RGBA
+	}{
+		"same layer": {
+			priorities: []float64{0, 0},
+			expected:   blue,
+		},
+		"crossing over": {
+			priorities: []float64{0, 1},
+			expected:   black,
+		},
+		"crossing under": {
+			priorities: []float64{1, 0},
+			expected:   blue,
+		},
+	}
+
+	wkts := []string{
+		"LINESTRING (0 50, 100 50)",
+		"LINESTRING (50 0, 50 100)",
+	}
+
+	style := Style{
+		LineWidth:   10,
+		FillColor:   blue,
+		StrokeWidth: 6,
+		StrokeColor: black,
+		LineCap:     LineCapButt,
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(m, m.Bounds(), &image.Uniform{red}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		var lines []LayeredLine
+		for i, wkt := range wkts {
+			g, err := gctx.NewGeomFromWKT(wkt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines = append(lines, LayeredLine{Geom: g, Style: style, Priority: tt.priorities[i]})
+		}
+
+		err := DrawLayeredLines(gc, lines, noscale)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// on the horizontal line inside the casing of the vertical one
+		if actual := m.RGBAAt(44, 50); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+
+		if actual := m.RGBAAt(50, 50); actual != blue {
+			t.Errorf("%v: crossing Expected [%+v]\nGot [%+v]", tname, blue, actual)
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/layered_lines_%v.png", strings.ReplaceAll(tname, " ", "_")), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package geom
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+// MarkerShape is one of the built in marker symbols.
+type MarkerShape int
+
+const (
+	MarkerCircle MarkerShape = iota
+	MarkerSquare
+	MarkerTriangle
+	MarkerStar
+	MarkerCross
+	MarkerDiamond
+)
+
+// Marker is the symbol drawn for a point, an Image or svg Path is drawn in place of the Shape.
+type Marker struct {
+	Shape    MarkerShape
+	Size     float64
+	Rotation float64
+	Image    image.Image
+	Path     string
+	Anchor   []float64
+}
+
+// shapes are drawn in a box one unit wide centred on the point.
+var markerShapes = map[MarkerShape][][]float64{
+	MarkerSquare: {
+		{-0.5, -0.5}, {0.5, -0.5}, {0.5, 0.5}, {-0.5, 0.5},
+	},
+	MarkerTriangle: {
+		{0, -0.5}, {0.5, 0.5}, {-0.5, 0.5},
+	},
+	MarkerDiamond: {
+		{0, -0.5}, {0.5, 0}, {0, 0.5}, {-0.5, 0},
+	},
+	MarkerCross: {
+		{-0.15, -0.5}, {0.15, -0.5}, {0.15, -0.15}, {0.5, -0.15}, {0.5, 0.15}, {0.15, 0.15},
+		{0.15, 0.5}, {-0.15, 0.5}, {-0.15, 0.15}, {-0.5, 0.15}, {-0.5, -0.15}, {-0.15, -0.15},
+	},
+	MarkerStar: starPoints(5, 0.5, 0.2),
+}
+
+// DrawMarker draws the Marker of style centred on pos, which is already in pixels.
+func DrawMarker(gc draw2d.GraphicContext, pos []float64, style Style) error {
+	m := style.Marker
+	if m.Size <= 0 {
+		m.Size = 2 * style.PointRadius
+	}
+
+	gc.Save()
+	defer gc.Restore()
+
+	gc.Translate(pos[0], pos[1])
+	gc.Rotate(m.Rotation * (math.Pi / 180))
+
+	switch {
+	case m.Image != nil:
+		drawMarkerImage(gc, m)
+		return nil
+
+	case m.Path != "":
+		return drawMarkerPath(gc, m, style)
+	}
+
+	if m.Size <= 0 {
+		return errors.New("marker: needs a Size or PointRadius")
+	}
+
+	gc.SetFillColor(style.fill())
+	gc.SetStrokeColor(style.stroke())
+	gc.SetLineWidth(style.StrokeWidth)
+	style.applyLine(gc)
+
+	ax, ay := m.anchor()
+	ox := (0.5 - ax) * m.Size
+	oy := (0.5 - ay) * m.Size
+
+	points, ok := markerShapes[m.Shape]
+	if !ok {
+		draw2dkit.Circle(gc, ox, oy, m.Size/2)
+		fillStrokePath(gc, style)
+
+		return nil
+	}
+
+	for i, p := range points {
+		x := ox + p[0]*m.Size
+		y := oy + p[1]*m.Size
+		if i == 0 {
+			gc.MoveTo(x, y)
+			continue
+		}
+		gc.LineTo(x, y)
+	}
+	gc.Close()
+
+	fillStrokePath(gc, style)
+
+	return nil
+}
+
+// isCircle is true for markers drawn as a plain circle of PointRadius.
+func (m Marker) isCircle() bool {
+	return m.Shape == MarkerCircle && m.Size == 0 && m.Image == nil && m.Path == "" && m.Anchor == nil
+}
+
+func (m Marker) anchor() (float64, float64) {
+	if len(m.Anchor) < 2 {
+		return 0.5, 0.5
+	}
+
+	return m.Anchor[0], m.Anchor[1]
+}
+
+func drawMarkerImage(gc draw2d.GraphicContext, m Marker) {
+	b := m.Image.Bounds()
+	w := float64(b.Dx())
+	h := float64(b.Dy())
+
+	s := 1.0
+	if m.Size > 0 {
+		s = m.Size / math.Max(w, h)
+	}
+
+	ax, ay := m.anchor()
+
+	gc.Scale(s, s)
+	gc.Translate(-float64(b.Min.X)-ax*w, -float64(b.Min.Y)-ay*h)
+
+	drawImage(gc, m.Image)
+}
+
+func drawMarkerPath(gc draw2d.GraphicContext, m Marker, style Style) error {
+	cmds, err := parseSVGPath(m.Path)
+	if err != nil {
+		return err
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+	for _, c := range cmds {
+		for _, p := range c.Points {
+			minX = math.Min(minX, p[0])
+			minY = math.Min(minY, p[1])
+			maxX = math.Max(maxX, p[0])
+			maxY = math.Max(maxY, p[1])
+		}
+	}
+
+	w := maxX - minX
+	h := maxY - minY
+
+	s := 1.0
+	if m.Size > 0 && math.Max(w, h) > 0 {
+		s = m.Size / math.Max(w, h)
+	}
+
+	ax, ay := m.anchor()
+
+	// the path is scaled here rather than on gc so the stroke width is left alone
+	tr := func(p []float64) (float64, float64) {
+		return (p[0] - minX - ax*w) * s, (p[1] - minY - ay*h) * s
+	}
+
+	gc.SetFillColor(style.fill())
+	gc.SetStrokeColor(style.stroke())
+	gc.SetLineWidth(style.StrokeWidth)
+	style.applyLine(gc)
+
+	for _, c := range cmds {
+		switch c.Op {
+		case 'M':
+			gc.MoveTo(tr(c.Points[0]))
+		case 'L':
+			gc.LineTo(tr(c.Points[0]))
+		case 'Q':
+			cx, cy := tr(c.Points[0])
+			x, y := tr(c.Points[1])
+			gc.QuadCurveTo(cx, cy, x, y)
+		case 'C':
+			c1x, c1y := tr(c.Points[0])
+			c2x, c2y := tr(c.Points[1])
+			x, y := tr(c.Points[2])
+			gc.CubicCurveTo(c1x, c1y, c2x, c2y, x, y)
+		case 'Z':
+			gc.Close()
+		}
+	}
+
+	fillStrokePath(gc, style)
+
+	return nil
+}
+
+// drawImage draws m at the origin of gc.
+func drawImage(gc draw2d.GraphicContext, m image.Image) {
+	img, ok := imageContext(gc)
+	if !ok {
+		gc.DrawImage(m)
+		return
+	}
+
+	// draw2dimg transposes the matrix when drawing images which turns them the wrong way
+	tr := img.GetMatrixTransform()
+	filter := img.Filter
+
+	img.SetMatrixTransform(draw2d.Matrix{tr[0], tr[2], tr[1], tr[3], tr[4], tr[5]})
+	img.Filter = draw2dimg.BilinearFilter
+	img.DrawImage(m)
+
+	img.SetMatrixTransform(tr)
+	img.Filter = filter
+}
+
+func starPoints(n int, outer, inner float64) [][]float64 {
+	res := make([][]float64, 0, 2*n)
+
+	for i := 0; i < 2*n; i++ {
+		r := outer
+		if i%2 == 1 {
+			r = inner
+		}
+
+		// the first point is straight up
+		a := float64(i)*math.Pi/float64(n) - math.Pi/2
+		res = append(res, []float64{r * math.Cos(a), r * math.Sin(a)})
+	}
+
+	return res
+}
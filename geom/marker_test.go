@@ -0,0 +1,301 @@
+package geom
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func TestDrawMarker(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	green := color.RGBA{0x00, 0xFF, 0x00, 0xFF}
+
+	icon := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(icon, icon.Bounds(), &image.Uniform{green}, image.Point{0, 0}, draw.Src)
+
+	type sample struct {
+		x, y     int
+		expected color.RGBA
+	}
+
+	tests := map[string]struct {
+		marker  Marker
+		radius  float64
+		samples []sample
+	}{
+		"circle": {
+			marker: Marker{Shape: MarkerCircle, Size: 20},
+			samples: []sample{
+				{50, 50, blue},
+				{58, 50, blue},
+				{42, 42, red},
+			},
+		},
+		"square": {
+			marker: Marker{Shape: MarkerSquare, Size: 20},
+			samples: []sample{
+				{50, 50, blue},
+				{41, 41, blue},
+				{58, 58, blue},
+			},
+		},
+		"square from radius": {
+			marker: Marker{Shape: MarkerSquare},
+			radius: 10,
+			samples: []sample{
+				{50, 50, blue},
+				{41, 41, blue},
+				{58, 58, blue},
+				{38, 38, red},
+			},
+		},
+		"rotated square": {
+			marker: Marker{Shape: MarkerSquare, Size: 20, Rotation: 45},
+			samples: []sample{
+				{50, 50, blue},
+				{41, 41, red},
+				{50, 37, blue},
+			},
+		},
+		"diamond": {
+			marker: Marker{Shape: MarkerDiamond, Size: 20},
+			samples: []sample{
+				{50, 50, blue},
+				{50, 42, blue},
+				{42, 42, red},
+			},
+		},
+		"triangle": {
+			marker: Marker{Shape: MarkerTriangle, Size: 20},
+			samples: []sample{
+				{50, 45, blue},
+				{42, 56, blue},
+				{42, 42, red},
+			},
+		},
+		"star": {
+			marker: Marker{Shape: MarkerStar, Size: 20},
+			samples: []sample{
+				{50, 50, blue},
+				{49, 44, blue},
+				{42, 42, red},
+			},
+		},
+		"cross": {
+			marker: Marker{Shape: MarkerCross, Size: 20},
+			samples: []sample{
+				{50, 42, blue},
+				{42, 50, blue},
+				{43, 43, red},
+			},
+		},
+		"image": {
+			marker: Marker{Image: icon, Size: 20},
+			samples: []sample{
+				{50, 50, green},
+				{42, 42, green},
+				{38, 38, red},
+			},
+		},
+		"anchored image": {
+			marker: Marker{Image: icon, Size: 20, Anchor: []float64{0.5, 1}},
+			samples: []sample{
+				{50, 40, green},
+				{50, 55, red},
+			},
+		},
+		"path": {
+			marker: Marker{Path: "M 0 0 L 10 0 l 0 10 Z", Size: 20},
+			samples: []sample{
+				{57, 42, blue},
+				{42, 57, red},
+			},
+		},
+		"curved path": {
+			marker: Marker{Path: "M0,0 C0,10 10,10 10,0 z", Size: 20},
+			samples: []sample{
+				{50, 50, blue},
+				{50, 60, red},
+			},
+		},
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(m, m.Bounds(), &image.Uniform{red}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		g, err := gctx.NewGeomFromWKT("POINT (50 50)")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		style := Style{
+			FillColor:   blue,
+			StrokeColor: blue,
+			PointRadius: tt.radius,
+			Marker:      tt.marker,
+		}
+
+		err = DrawPointWithStyle(gc, g, style, noscale)
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		for _, s := range tt.samples {
+			if actual := m.RGBAAt(s.x, s.y); actual != s.expected {
+				t.Errorf("%v: at %v,%v Expected [%+v]\nGot [%+v]", tname, s.x, s.y, s.expected, actual)
+			}
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/marker_%v.png", strings.ReplaceAll(tname, " ", "_")), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 100, 100)))
+
+	err := DrawMarker(gc, []float64{50, 50}, Style{FillColor: blue, Marker: Marker{Shape: MarkerSquare}})
+	if err == nil {
+		t.Error("expected an error for a marker with no size")
+	}
+}
+
+func TestParseSVGPath(t *testing.T) {
+	tests := map[string]struct {
+		d        string
+		expected string
+	}{
+		"absolute": {
+			d:        "M 1 2 L 3 4 Z",
+			expected: "M[[1 2]] L[[3 4]] Z[]",
+		},
+		"relative": {
+			d:        "m1,2 l2,2 h-3 v1",
+			expected: "M[[1 2]] L[[3 4]] L[[0 4]] L[[0 5]]",
+		},
+		"implicit lines": {
+			d:        "M0 0 1 1 2-2",
+			expected: "M[[0 0]] L[[1 1]] L[[2 -2]]",
+		},
+		"smooth curve": {
+			d:        "M0 0 C0 1 1 1 1 0 S2 -1 2 0",
+			expected: "M[[0 0]] C[[0 1] [1 1] [1 0]] C[[1 -1] [2 -1] [2 0]]",
+		},
+		"smooth quadratic": {
+			d:        "M0 0 Q1 1 2 0 T4 0 t2 0",
+			expected: "M[[0 0]] Q[[1 1] [2 0]] Q[[3 -1] [4 0]] Q[[5 1] [6 0]]",
+		},
+		"flat arc": {
+			d:        "M0 0 A0 1 0 0 0 1 1",
+			expected: "M[[0 0]] C[[0 0] [1 1] [1 1]]",
+		},
+		"missing numbers": {
+			d:        "M0 0 L1",
+			expected: "error",
+		},
+	}
+
+	for tname, tt := range tests {
+		cmds, err := parseSVGPath(tt.d)
+
+		var parts []string
+		for _, c := range cmds {
+			parts = append(parts, fmt.Sprintf("%c%v", c.Op, c.Points))
+		}
+
+		actual := strings.Join(parts, " ")
+		if err != nil {
+			actual = "error"
+		}
+
+		if actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+}
+
+func TestParseSVGPathArc(t *testing.T) {
+	tests := map[string]struct {
+		d       string
+		center  []float64
+		radius  float64
+		curves  int
+		through []float64
+	}{
+		"half circle": {
+			d:       "M0 0 A1 1 0 0 1 2 0",
+			center:  []float64{1, 0},
+			radius:  1,
+			curves:  2,
+			through: []float64{1, -1},
+		},
+		"other way round": {
+			d:       "M0 0 a1 1 0 0 0 2 0",
+			center:  []float64{1, 0},
+			radius:  1,
+			curves:  2,
+			through: []float64{1, 1},
+		},
+		"large arc": {
+			d:       "M10 0 A10 10 0 1 1 0 10",
+			center:  []float64{10, 10},
+			radius:  10,
+			curves:  3,
+			through: []float64{20, 10},
+		},
+		"radius too small": {
+			d:       "M0 0 A0.5 0.5 0 0 1 2 0",
+			center:  []float64{1, 0},
+			radius:  1,
+			curves:  2,
+			through: []float64{1, -1},
+		},
+	}
+
+	for tname, tt := range tests {
+		cmds, err := parseSVGPath(tt.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		curves := cmds[1:]
+		if len(curves) != tt.curves {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.curves, len(curves))
+			continue
+		}
+
+		through := false
+		start := cmds[0].Points[0]
+		for i, c := range curves {
+			// the middle of each curve should be on the circle too
+			p := c.Points
+			mx := (start[0] + 3*p[0][0] + 3*p[1][0] + p[2][0]) / 8
+			my := (start[1] + 3*p[0][1] + 3*p[1][1] + p[2][1]) / 8
+
+			for _, pt := range [][]float64{p[2], {mx, my}} {
+				d := math.Hypot(pt[0]-tt.center[0], pt[1]-tt.center[1])
+				if math.Abs(d-tt.radius) > tt.radius/1000 {
+					t.Errorf("%v: curve %v: Expected [%+v]\nGot [%+v]", tname, i, tt.radius, d)
+				}
+			}
+
+			if math.Hypot(p[2][0]-tt.through[0], p[2][1]-tt.through[1]) < 1e-9 {
+				through = true
+			}
+
+			start = p[2]
+		}
+
+		if !through {
+			t.Errorf("%v: expected the arc to pass through %v", tname, tt.through)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package geom
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	geos "github.com/twpayne/go-geos"
+)
+
+type PatternKind int
+
+const (
+	PatternNone PatternKind = iota
+	PatternHatch
+	PatternCrossHatch
+	PatternDots
+)
+
+// FillPattern is painted over the fill of a polygon, an Image is tiled in place of the Kind.
+type FillPattern struct {
+	Kind      PatternKind
+	Spacing   float64
+	Angle     float64
+	LineWidth float64
+	Color     color.Color
+	Image     image.Image
+}
+
+const defaultPatternSpacing = 8
+
+func (p FillPattern) isSet() bool {
+	return p.Kind != PatternNone || p.Image != nil
+}
+
+func (p FillPattern) spacing() float64 {
+	if p.Spacing <= 0 {
+		return defaultPatternSpacing
+	}
+
+	return p.Spacing
+}
+
+func (p FillPattern) lineWidth() float64 {
+	if p.LineWidth <= 0 {
+		return 1
+	}
+
+	return p.LineWidth
+}
+
+// drawPattern paints the pattern of style over the polygon g.
+func drawPattern(gc draw2d.GraphicContext, g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) {
+	rings := pixelRings(g, style, scale)
+	if len(rings) == 0 {
+		return
+	}
+
+	p := style.Pattern
+
+	gc.Save()
+	defer gc.Restore()
+
+	if p.Image != nil {
+		drawPatternImage(gc, rings, style)
+		return
+	}
+
+	c := p.Color
+	if c == nil {
+		c = style.StrokeColor
+	}
+	if c == nil {
+		c = color.Black
+	}
+
+	gc.SetFillColor(style.withOpacity(c))
+	gc.SetFillRule(style.FillRule)
+
+	minX, minY, maxX, maxY := ringsBounds(rings)
+
+	var layers [][][][]float64
+
+	//nolint:exhaustive
+	switch p.Kind {
+	case PatternHatch:
+		layers = append(layers, hatchStripes(minX, minY, maxX, maxY, p.spacing(), p.Angle, p.lineWidth()))
+
+	case PatternCrossHatch:
+		layers = append(layers,
+			hatchStripes(minX, minY, maxX, maxY, p.spacing(), p.Angle, p.lineWidth()),
+			hatchStripes(minX, minY, maxX, maxY, p.spacing(), p.Angle+90, p.lineWidth()),
+		)
+
+	case PatternDots:
+		layers = append(layers, patternDots(minX, minY, maxX, maxY, p.spacing(), p.Angle, p.lineWidth()/2))
+	}
+
+	// each layer is one path so the pieces of a layer are only painted once
+	for _, clips := range layers {
+		for _, clip := range clips {
+			for _, ring := range rings {
+				fillPathRing(gc, clipRing(ring, clip))
+			}
+		}
+		gc.Fill()
+	}
+}
+
+// pixelRings returns every ring of g in pixels wound the way DrawPolygonWithStyle winds them.
+func pixelRings(g *geos.Geom, style Style, scale func(x, y float64) (float64, float64)) [][][]float64 {
+	var res [][][]float64
+
+	for _, rings := range GetParts(g) {
+		for i, ring := range rings {
+			ring := ring
+			cs := &ring
+			if style.FillRule == draw2d.FillRuleWinding {
+				cs = orientRing(cs, i == 0)
+			}
+
+			if len(*cs) == 0 {
+				continue
+			}
+
+			res = append(res, transformCoords(*cs, scale))
+		}
+	}
+
+	return res
+}
+
+func fillPathRing(gc draw2d.GraphicContext, ring [][]float64) {
+	if len(ring) < 3 {
+		return
+	}
+
+	gc.MoveTo(ring[0][0], ring[0][1])
+	for _, p := range ring[1:] {
+		gc.LineTo(p[0], p[1])
+	}
+	gc.Close()
+}
+
+func ringsBounds(rings [][][]float64) (float64, float64, float64, float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+	for _, ring := range rings {
+		for _, p := range ring {
+			minX = math.Min(minX, p[0])
+			minY = math.Min(minY, p[1])
+			maxX = math.Max(maxX, p[0])
+			maxY = math.Max(maxY, p[1])
+		}
+	}
+
+	return minX, minY, maxX, maxY
+}
+
+// hatchStripes covers the box with rectangles width wide and spacing apart at angle degrees.
+func hatchStripes(minX, minY, maxX, maxY, spacing, angle, width float64) [][][]float64 {
+	sin, cos := math.Sincos(angle * (math.Pi / 180))
+
+	// u runs along the lines and v across them
+	umin, umax, vmin, vmax := rotatedBounds(minX, minY, maxX, maxY, sin, cos)
+
+	at := func(u, v float64) []float64 {
+		return []float64{u*cos - v*sin, u*sin + v*cos}
+	}
+
+	var res [][][]float64
+
+	hw := width / 2
+	for k := math.Ceil((vmin - hw) / spacing); k*spacing-hw <= vmax; k++ {
+		v := k * spacing
+
+		res = append(res, [][]float64{
+			at(umin, v-hw),
+			at(umax, v-hw),
+			at(umax, v+hw),
+			at(umin, v+hw),
+		})
+	}
+
+	return res
+}
+
+// patternDots covers the box with a grid of dots spacing apart turned by angle degrees.
+func patternDots(minX, minY, maxX, maxY, spacing, angle, radius float64) [][][]float64 {
+	sin, cos := math.Sincos(angle * (math.Pi / 180))
+
+	umin, umax, vmin, vmax := rotatedBounds(minX, minY, maxX, maxY, sin, cos)
+
+	var res [][][]float64
+
+	for k := math.Ceil((vmin - radius) / spacing); k*spacing-radius <= vmax; k++ {
+		for j := math.Ceil((umin - radius) / spacing); j*spacing-radius <= umax; j++ {
+			u := j * spacing
+			v := k * spacing
+
+			x := u*cos - v*sin
+			y := u*sin + v*cos
+
+			// the corners of the rotated grid fall outside the box
+			if x+radius < minX || x-radius > maxX || y+radius < minY || y-radius > maxY {
+				continue
+			}
+
+			res = append(res, circlePolygon(x, y, radius))
+		}
+	}
+
+	return res
+}
+
+// rotatedBounds is the extent of the box along and across lines at the angle given by sin and cos.
+func rotatedBounds(minX, minY, maxX, maxY, sin, cos float64) (float64, float64, float64, float64) {
+	umin, vmin := math.Inf(1), math.Inf(1)
+	umax, vmax := math.Inf(-1), math.Inf(-1)
+
+	for _, c := range [][]float64{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}} {
+		u := c[0]*cos + c[1]*sin
+		v := -c[0]*sin + c[1]*cos
+
+		umin = math.Min(umin, u)
+		umax = math.Max(umax, u)
+		vmin = math.Min(vmin, v)
+		vmax = math.Max(vmax, v)
+	}
+
+	return umin, umax, vmin, vmax
+}
+
+// clipRing keeps the part of ring inside the convex polygon clip, in the same winding.
+func clipRing(ring, clip [][]float64) [][]float64 {
+	dir := 1.0
+	if signedArea(clip) < 0 {
+		dir = -1
+	}
+
+	res := ring
+
+	for i := range clip {
+		if len(res) == 0 {
+			break
+		}
+
+		a := clip[i]
+		b := clip[(i+1)%len(clip)]
+
+		// side is positive inside the edge a to b
+		side := func(p []float64) float64 {
+			return dir * ((b[0]-a[0])*(p[1]-a[1]) - (b[1]-a[1])*(p[0]-a[0]))
+		}
+
+		in := res
+		res = nil
+
+		prev := in[len(in)-1]
+		ps := side(prev)
+
+		for _, p := range in {
+			s := side(p)
+
+			if (s >= 0) != (ps >= 0) {
+				t := ps / (ps - s)
+				res = append(res, []float64{prev[0] + t*(p[0]-prev[0]), prev[1] + t*(p[1]-prev[1])})
+			}
+
+			if s >= 0 {
+				res = append(res, p)
+			}
+
+			prev, ps = p, s
+		}
+	}
+
+	return res
+}
+
+// drawPatternImage tiles the image of the pattern over the rings as one masked image.
+func drawPatternImage(gc draw2d.GraphicContext, rings [][][]float64, style Style) {
+	tile := style.Pattern.Image
+	tb := tile.Bounds()
+	if tb.Empty() {
+		return
+	}
+
+	minX, minY, maxX, maxY := ringsBounds(rings)
+	r := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	if r.Empty() {
+		return
+	}
+
+	// draw2dimg only draws to images starting at the origin
+	mask := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	mgc := draw2dimg.NewGraphicContext(mask)
+	mgc.Translate(-float64(r.Min.X), -float64(r.Min.Y))
+	mgc.SetFillColor(style.withOpacity(color.White))
+	mgc.SetFillRule(style.FillRule)
+	for _, ring := range rings {
+		fillPathRing(mgc, ring)
+	}
+	mgc.Fill()
+
+	tiled := image.NewRGBA(r)
+	w, h := tb.Dx(), tb.Dy()
+
+	// tiles start on multiples of their size
+	x0 := int(math.Floor(float64(r.Min.X)/float64(w))) * w
+	y0 := int(math.Floor(float64(r.Min.Y)/float64(h))) * h
+	for y := y0; y < r.Max.Y; y += h {
+		for x := x0; x < r.Max.X; x += w {
+			draw.Draw(tiled, image.Rect(x, y, x+w, y+h), tile, tb.Min, draw.Src)
+		}
+	}
+
+	m := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.DrawMask(m, m.Bounds(), tiled, r.Min, mask, image.Point{0, 0}, draw.Src)
+
+	gc.Translate(float64(r.Min.X), float64(r.Min.Y))
+	drawImage(gc, m)
+}
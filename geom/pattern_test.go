@@ -0,0 +1,216 @@
+package geom
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dsvg"
+)
+
+func TestDrawPolygonPattern(t *testing.T) {
+	grey := color.RGBA{0xEE, 0xEE, 0xEE, 0xFF}
+	green := color.RGBA{0x00, 0xFF, 0x00, 0xFF}
+	yellow := color.RGBA{0xFF, 0xFF, 0x00, 0xFF}
+
+	tile := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(tile, image.Rect(0, 0, 10, 5), &image.Uniform{green}, image.Point{0, 0}, draw.Src)
+	draw.Draw(tile, image.Rect(0, 5, 10, 10), &image.Uniform{yellow}, image.Point{0, 0}, draw.Src)
+
+	type sample struct {
+		x, y     int
+		expected color.RGBA
+	}
+
+	tests := map[string]struct {
+		pattern FillPattern
+		samples []sample
+	}{
+		"hatch": {
+			pattern: FillPattern{Kind: PatternHatch, Spacing: 10, LineWidth: 2},
+			samples: []sample{
+				{30, 50, blue},
+				{30, 49, blue},
+				{30, 55, grey},
+				{70, 50, blue},
+				{70, 70, white},
+				{130, 50, white},
+			},
+		},
+		"cross hatch": {
+			pattern: FillPattern{Kind: PatternCrossHatch, Spacing: 10, LineWidth: 2},
+			samples: []sample{
+				{35, 50, blue},
+				{50, 35, blue},
+				{35, 35, grey},
+				{70, 70, white},
+				{50, 130, white},
+			},
+		},
+		"dots": {
+			pattern: FillPattern{Kind: PatternDots, Spacing: 10, LineWidth: 4},
+			samples: []sample{
+				{50, 50, blue},
+				{49, 49, blue},
+				{55, 55, grey},
+				{55, 50, grey},
+				{70, 70, white},
+			},
+		},
+		"angled hatch": {
+			pattern: FillPattern{Kind: PatternHatch, Spacing: 10, LineWidth: 2, Angle: 45},
+			samples: []sample{
+				{30, 30, blue},
+				{35, 30, grey},
+				{130, 130, white},
+			},
+		},
+		"image": {
+			pattern: FillPattern{Image: tile},
+			samples: []sample{
+				{30, 22, green},
+				{30, 27, yellow},
+				{119, 119, yellow},
+				{70, 70, white},
+				{130, 22, white},
+			},
+		},
+	}
+
+	g, err := gctx.NewGeomFromWKT("POLYGON ((20 20, 120 20, 120 120, 20 120, 20 20), (60 60, 80 60, 80 80, 60 80, 60 60))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 150, 150))
+		draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		pattern := tt.pattern
+		pattern.Color = blue
+
+		style := Style{
+			FillColor: grey,
+			Pattern:   pattern,
+		}
+
+		err = DrawPolygonWithStyle(gc, g, style, noscale)
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		for _, s := range tt.samples {
+			if actual := m.RGBAAt(s.x, s.y); actual != s.expected {
+				t.Errorf("%v: at %v,%v Expected [%+v]\nGot [%+v]", tname, s.x, s.y, s.expected, actual)
+			}
+		}
+
+		err = savePNG(fmt.Sprintf("test-output/pattern_%v.png", strings.ReplaceAll(tname, " ", "_")), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDrawPolygonNoFill(t *testing.T) {
+	tests := map[string]struct {
+		pattern FillPattern
+		inside  color.RGBA
+	}{
+		"outline": {
+			inside: white,
+		},
+		"hatch": {
+			pattern: FillPattern{Kind: PatternHatch, Spacing: 10, LineWidth: 2},
+			inside:  black,
+		},
+	}
+
+	g, err := gctx.NewGeomFromWKT("POLYGON ((20 20, 120 20, 120 120, 20 120, 20 20))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 150, 150))
+		draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+		gc := draw2dimg.NewGraphicContext(m)
+
+		// with no fill only the pattern and outline are drawn
+		style := Style{
+			StrokeColor: black,
+			StrokeWidth: 2,
+			Pattern:     tt.pattern,
+		}
+
+		err = DrawPolygonWithStyle(gc, g, style, noscale)
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		samples := map[string]struct {
+			x, y     int
+			expected color.RGBA
+		}{
+			"outline": {20, 70, black},
+			"inside":  {70, 50, tt.inside},
+			"gap":     {70, 55, white},
+		}
+
+		for sname, s := range samples {
+			if actual := m.RGBAAt(s.x, s.y); actual != s.expected {
+				t.Errorf("%v %v: Expected [%+v]\nGot [%+v]", tname, sname, s.expected, actual)
+			}
+		}
+
+		svg := draw2dsvg.NewGraphicContext(draw2dsvg.NewSvg())
+
+		err = DrawPolygonWithStyle(svg, g, style, noscale)
+		if err != nil {
+			t.Fatalf("%v svg: %v", tname, err)
+		}
+	}
+}
+
+func TestClipRing(t *testing.T) {
+	square := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+
+	tests := map[string]struct {
+		clip     [][]float64
+		expected float64
+	}{
+		"inside": {
+			clip:     [][]float64{{-5, -5}, {15, -5}, {15, 15}, {-5, 15}},
+			expected: 100,
+		},
+		"stripe": {
+			clip:     [][]float64{{-5, 4}, {15, 4}, {15, 6}, {-5, 6}},
+			expected: 20,
+		},
+		"clockwise stripe": {
+			clip:     [][]float64{{-5, 6}, {15, 6}, {15, 4}, {-5, 4}},
+			expected: 20,
+		},
+		"outside": {
+			clip:     [][]float64{{20, 20}, {30, 20}, {30, 30}, {20, 30}},
+			expected: 0,
+		},
+	}
+
+	for tname, tt := range tests {
+		var actual float64
+		if res := clipRing(square, tt.clip); len(res) > 0 {
+			actual = signedArea(res)
+		}
+
+		if math.Abs(actual-tt.expected) > 1e-9 {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package geom
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+	"github.com/llgcode/draw2d/draw2dpdf"
+	geos "github.com/twpayne/go-geos"
+)
+
+// PageText is drawn as real text, Pos is in world coordinates.
+type PageText struct {
+	Text     string
+	Pos      []float64
+	Rotation float64
+	FontSize float64
+}
+
+// Page is one page of a pdf, each page has its own envelope.
+type Page struct {
+	Envelope Envelope
+	Geoms    []*geos.Geom
+	Styles   []Style
+	Texts    []PageText
+}
+
+// RenderPDF draws every page at width x height points using the same drawing as Render.
+func RenderPDF(pages []Page, width, height float64) (*gofpdf.Fpdf, error) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "pt",
+		Size: gofpdf.SizeType{
+			Wd: width,
+			Ht: height,
+		},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", 12)
+
+	gc := &pdfContext{
+		GraphicContext: draw2dpdf.NewGraphicContext(pdf),
+		pdf:            pdf,
+	}
+
+	// draw2dpdf scales font sizes by dpi / 24
+	gc.SetDPI(24)
+
+	for i, page := range pages {
+		pdf.AddPage()
+
+		// every page starts from the pdf default
+		gc.miterLimit = 0
+
+		gc.SetFillColor(color.White)
+		draw2dkit.Rectangle(gc, 0, 0, width, height)
+		gc.Fill()
+
+		err := drawStyledGeoms(gc, page.Geoms, page.Styles, page.Envelope, width, height)
+		if err != nil {
+			return pdf, fmt.Errorf("page %v: %w", i+1, err)
+		}
+
+		viewport := NewViewport(page.Envelope, width, height)
+
+		for _, t := range page.Texts {
+			if t.FontSize > 0 {
+				gc.SetFontSize(t.FontSize)
+			}
+
+			x, y := viewport.Scale(t.Pos[0], t.Pos[1])
+
+			err = DrawString(gc, []float64{x, y}, t.Rotation, t.Text)
+			if err != nil {
+				return pdf, fmt.Errorf("page %v: %w", i+1, err)
+			}
+		}
+	}
+
+	return pdf, pdf.Error()
+}
+
+// pdfContext writes the miter limit draw2dpdf has no call for, kept in step with Save and Restore.
+type pdfContext struct {
+	*draw2dpdf.GraphicContext
+	pdf        *gofpdf.Fpdf
+	miterLimit float64
+	saved      []float64
+}
+
+func (gc *pdfContext) Unwrap() draw2d.GraphicContext {
+	return gc.GraphicContext
+}
+
+func (gc *pdfContext) Save() {
+	gc.GraphicContext.Save()
+	gc.saved = append(gc.saved, gc.miterLimit)
+}
+
+func (gc *pdfContext) Restore() {
+	gc.GraphicContext.Restore()
+
+	if n := len(gc.saved); n > 0 {
+		gc.miterLimit = gc.saved[n-1]
+		gc.saved = gc.saved[:n-1]
+	}
+}
+
+func (gc *pdfContext) SetMiterLimit(limit float64) {
+	if limit == gc.miterLimit {
+		return
+	}
+
+	gc.miterLimit = limit
+	gc.pdf.RawWriteStr(fmt.Sprintf("%.2f M", limit))
+}
@@ -0,0 +1,121 @@
+package geom
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/llgcode/draw2d/draw2dpdf"
+)
+
+func TestRenderPDF(t *testing.T) {
+	geoms, styles := renderTestData(t)
+
+	pages := []Page{
+		{
+			Envelope: Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}},
+			Geoms:    geoms,
+			Styles:   styles,
+			Texts: []PageText{
+				{Text: "Whole Map", Pos: []float64{5, 55}, FontSize: 12},
+			},
+		},
+		{
+			Envelope: Envelope{Min: []float64{20, 20}, Max: []float64{40, 40}},
+			Geoms:    geoms,
+			Styles:   styles,
+			Texts: []PageText{
+				{Text: "Zoomed In", Pos: []float64{21, 38}, FontSize: 12},
+			},
+		},
+	}
+
+	pdf, err := RenderPDF(pages, 600, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pdf.PageNo() != len(pages) {
+		t.Errorf("expected %v pages, got %v", len(pages), pdf.PageNo())
+	}
+
+	pdf.SetCompression(false)
+
+	var b bytes.Buffer
+	err = pdf.Output(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// text is written as text rather than paths
+	for _, p := range pages {
+		if !bytes.Contains(b.Bytes(), []byte(p.Texts[0].Text)) {
+			t.Errorf("expected %q in pdf", p.Texts[0].Text)
+		}
+	}
+
+	err = saveFile("test-output/render.pdf", b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderPDFMiterLimit(t *testing.T) {
+	geoms, styles := renderTestData(t)
+
+	for i := range styles {
+		styles[i].MiterLimit = 10
+	}
+
+	pdf, err := RenderPDF([]Page{{Envelope: Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}}, Geoms: geoms, Styles: styles}}, 600, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdf.SetCompression(false)
+
+	var b bytes.Buffer
+	err = pdf.Output(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(b.Bytes(), []byte("10.00 M")) {
+		t.Error("expected the miter limit to be set in the pdf")
+	}
+}
+
+func TestPDFContextMiterLimit(t *testing.T) {
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.AddPage()
+	pdf.SetCompression(false)
+
+	gc := &pdfContext{GraphicContext: draw2dpdf.NewGraphicContext(pdf), pdf: pdf}
+
+	gc.SetMiterLimit(6)
+	gc.SetMiterLimit(6)
+
+	// Q undoes the limit set after q so it has to be written again
+	gc.Save()
+	gc.SetMiterLimit(8)
+	gc.Restore()
+	gc.SetMiterLimit(8)
+
+	var b bytes.Buffer
+	err := pdf.Output(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]int{
+		"6.00 M": 1,
+		"8.00 M": 2,
+	}
+
+	for op, expected := range tests {
+		got := bytes.Count(b.Bytes(), []byte(op))
+		if got != expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", op, expected, got)
+		}
+	}
+}
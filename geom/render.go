@@ -0,0 +1,125 @@
+package geom
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dkit"
+	"github.com/llgcode/draw2d/draw2dsvg"
+	geos "github.com/twpayne/go-geos"
+)
+
+type styledGeom struct {
+	geom  *geos.Geom
+	style Style
+}
+
+// Render draws geoms onto a new image, styles either holds one style per geometry or a
+// single style used for all of them. Polygons are drawn first, then lines, then points.
+func Render(geoms []*geos.Geom, styles []Style, envelope Envelope, width, height int) (*image.RGBA, error) {
+	m := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(m, m.Bounds(), &image.Uniform{color.White}, image.Point{0, 0}, draw.Src)
+	gc := draw2dimg.NewGraphicContext(m)
+
+	gc.SetDPI(72)
+
+	err := drawStyledGeoms(gc, geoms, styles, envelope, float64(width), float64(height))
+
+	return m, err
+}
+
+// RenderSVG is the vector equivalent of Render, the geometry drawn is identical.
+func RenderSVG(geoms []*geos.Geom, styles []Style, envelope Envelope, width, height int) (*draw2dsvg.Svg, error) {
+	s := draw2dsvg.NewSvg()
+	s.Width = fmt.Sprintf("%vpx", width)
+	s.Height = fmt.Sprintf("%vpx", height)
+	s.ViewBox = fmt.Sprintf("0 0 %v %v", width, height)
+	gc := draw2dsvg.NewGraphicContext(s)
+
+	gc.SetDPI(72)
+
+	gc.SetFillColor(color.White)
+	draw2dkit.Rectangle(gc, 0, 0, float64(width), float64(height))
+	gc.Fill()
+
+	err := drawStyledGeoms(gc, geoms, styles, envelope, float64(width), float64(height))
+
+	return s, err
+}
+
+func drawStyledGeoms(gc draw2d.GraphicContext, geoms []*geos.Geom, styles []Style, envelope Envelope, width, height float64) error {
+	if len(styles) != 1 && len(styles) != len(geoms) {
+		return fmt.Errorf("expected 1 or %v styles, got %v", len(geoms), len(styles))
+	}
+
+	viewport := NewViewport(envelope, width, height)
+
+	var sgs []styledGeom
+	for i, g := range geoms {
+		style := styles[0]
+		if len(styles) > 1 {
+			style = styles[i]
+		}
+		sgs = appendStyledGeoms(sgs, g, style)
+	}
+
+	sort.SliceStable(sgs, func(i, j int) bool {
+		return drawOrder(sgs[i].geom) < drawOrder(sgs[j].geom)
+	})
+
+	// lines are drawn together so all of their casings go beneath all of their fills
+	var lines []LayeredLine
+
+	for _, sg := range sgs {
+		if drawOrder(sg.geom) == 1 {
+			lines = append(lines, LayeredLine{Geom: sg.geom, Style: sg.style})
+			continue
+		}
+
+		err := DrawLayeredLines(gc, lines, viewport.Scale)
+		if err != nil {
+			return err
+		}
+		lines = nil
+
+		// a style only sets the line settings it uses so each geometry starts from the same gc
+		gc.Save()
+		err = DrawGeometry(gc, sg.geom, sg.style, viewport.Scale)
+		gc.Restore()
+		if err != nil {
+			return err
+		}
+	}
+
+	return DrawLayeredLines(gc, lines, viewport.Scale)
+}
+
+// appendStyledGeoms splits collections up so their members are ordered with everything else.
+func appendStyledGeoms(sgs []styledGeom, g *geos.Geom, style Style) []styledGeom {
+	if g.TypeID() != geos.TypeIDGeometryCollection {
+		return append(sgs, styledGeom{g, style})
+	}
+
+	for i := 0; i < g.NumGeometries(); i++ {
+		sgs = appendStyledGeoms(sgs, g.Geometry(i), style)
+	}
+
+	return sgs
+}
+
+//nolint:exhaustive
+func drawOrder(g *geos.Geom) int {
+	switch g.TypeID() {
+	case geos.TypeIDPolygon, geos.TypeIDMultiPolygon:
+		return 0
+	case geos.TypeIDLineString, geos.TypeIDLinearRing, geos.TypeIDMultiLineString:
+		return 1
+	}
+
+	return 2
+}
@@ -0,0 +1,180 @@
+package geom
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dsvg"
+	geos "github.com/twpayne/go-geos"
+)
+
+func TestRender(t *testing.T) {
+	wkts := []string{
+		"POINT (30 30)",
+		"LINESTRING (0 30, 60 30)",
+		"POLYGON ((10 10, 50 10, 50 50, 10 50, 10 10))",
+	}
+
+	geoms := []*geos.Geom{}
+	for _, wkt := range wkts {
+		g, err := gctx.NewGeomFromWKT(wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		geoms = append(geoms, g)
+	}
+
+	styles := []Style{
+		{FillColor: black, StrokeColor: black, PointRadius: 20},
+		{FillColor: white, StrokeColor: white, LineWidth: 10},
+		{FillColor: blue, StrokeColor: blue},
+	}
+
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}}
+
+	m, err := Render(geoms, styles, envelope, 600, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Bounds().Dx() != 600 || m.Bounds().Dy() != 400 {
+		t.Fatalf("expected 600 x 400, got %v", m.Bounds())
+	}
+
+	tests := map[string]struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		"background": {5, 5, white},
+		"polygon":    {150, 100, blue},
+		"line":       {150, 200, white},
+		"point":      {300, 200, black},
+	}
+
+	for tname, tt := range tests {
+		if actual := m.RGBAAt(tt.x, tt.y); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+
+	_, err = Render(geoms, styles[:2], envelope, 600, 400)
+	if err == nil {
+		t.Error("expected an error for mismatched styles")
+	}
+
+	err = savePNG("test-output/render.png", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	geoms, styles := renderTestData(t)
+
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}}
+
+	s, err := RenderSVG(geoms, styles, envelope, 600, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Width != "600px" || s.Height != "400px" {
+		t.Errorf("expected 600px x 400px, got %v x %v", s.Width, s.Height)
+	}
+
+	err = saveSVG("test-output/render.svg", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSVGMatchesPNG(t *testing.T) {
+	geoms, styles := renderTestData(t)
+
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{60, 60}}
+
+	png := &pathRecorder{GraphicContext: draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 600, 400)))}
+	svg := &pathRecorder{GraphicContext: draw2dsvg.NewGraphicContext(draw2dsvg.NewSvg())}
+
+	err := drawStyledGeoms(png, geoms, styles, envelope, 600, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = drawStyledGeoms(svg, geoms, styles, envelope, 600, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(png.paths) == 0 {
+		t.Fatal("nothing was drawn")
+	}
+
+	if !reflect.DeepEqual(png.paths, svg.paths) {
+		t.Errorf("Expected [%v]\nGot [%v]", strings.Join(png.paths, "\n"), strings.Join(svg.paths, "\n"))
+	}
+}
+
+func renderTestData(t *testing.T) ([]*geos.Geom, []Style) {
+	t.Helper()
+
+	wkts := []string{
+		"POINT (30 30)",
+		"MULTILINESTRING ((0 30, 60 30), (30 0, 30 60))",
+		"POLYGON ((10 10, 50 10, 50 50, 10 50, 10 10), (20 20, 40 20, 40 40, 20 20))",
+	}
+
+	geoms := []*geos.Geom{}
+	for _, wkt := range wkts {
+		g, err := gctx.NewGeomFromWKT(wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		geoms = append(geoms, g)
+	}
+
+	styles := []Style{
+		{FillColor: black, StrokeColor: black, PointRadius: 20},
+		{FillColor: white, StrokeColor: black, StrokeWidth: 2, LineWidth: 10},
+		{FillColor: blue, StrokeColor: black, StrokeWidth: 1},
+	}
+
+	return geoms, styles
+}
+
+// pathRecorder keeps a copy of every path painted so backends can be compared.
+type pathRecorder struct {
+	draw2d.GraphicContext
+	paths []string
+}
+
+func (r *pathRecorder) Unwrap() draw2d.GraphicContext {
+	return r.GraphicContext
+}
+
+func (r *pathRecorder) Stroke(paths ...*draw2d.Path) {
+	r.record("stroke", paths)
+	r.GraphicContext.Stroke(paths...)
+}
+
+func (r *pathRecorder) Fill(paths ...*draw2d.Path) {
+	r.record("fill", paths)
+	r.GraphicContext.Fill(paths...)
+}
+
+func (r *pathRecorder) FillStroke(paths ...*draw2d.Path) {
+	r.record("fillstroke", paths)
+	r.GraphicContext.FillStroke(paths...)
+}
+
+func (r *pathRecorder) record(op string, paths []*draw2d.Path) {
+	current := r.GetPath()
+	for _, p := range append(paths, &current) {
+		r.paths = append(r.paths, op+" "+p.String())
+	}
+}
@@ -0,0 +1,22 @@
+package geom
+
+import (
+	geos "github.com/twpayne/go-geos"
+)
+
+// Renderer holds a geos.Context of its own. go-geos locks a Context for every call so the
+// package functions are safe to use from many goroutines, parsing through a Renderer per
+// goroutine just keeps them from queuing on the same lock.
+type Renderer struct {
+	ctx *geos.Context
+}
+
+func NewRenderer() *Renderer {
+	return &Renderer{
+		ctx: geos.NewContext(),
+	}
+}
+
+func (r *Renderer) Context() *geos.Context {
+	return r.ctx
+}
@@ -0,0 +1,84 @@
+package geom
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+	"testing"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func TestRendererConcurrent(t *testing.T) {
+	wkt := "GEOMETRYCOLLECTION (POLYGON ((380600 413780, 380650 413780, 380650 413820, 380600 413820, 380600 413780), (380620 413790, 380630 413790, 380630 413800, 380620 413790)), MULTILINESTRING ((380626 413800, 380627 413806, 380632 413810), (380642 413807, 380643 413801)), POINT (380638 413795))"
+
+	numTiles := 32
+
+	var wg sync.WaitGroup
+	errs := make(chan error, numTiles)
+
+	for i := 0; i < numTiles; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// geometries are parsed in a context per goroutine, everything else shares the package one
+			r := NewRenderer()
+
+			bounds, err := BoundsGeom(380596, 380656, 413770, 413830)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			envelope, err := ToEnvelope(bounds)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			viewport := NewViewport(envelope, 256, 256)
+
+			g, err := r.Context().NewGeomFromWKT(wkt)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			_, err = ScaleLine(g.Geometry(1), viewport.Scale)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			_, err = GetGeometryCenter(g.Geometry(2), viewport.Scale)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			m := image.NewRGBA(image.Rect(0, 0, 256, 256))
+			draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+			gc := draw2dimg.NewGraphicContext(m)
+
+			style := Style{
+				FillColor:   blue,
+				StrokeColor: black,
+				StrokeWidth: 1,
+				LineWidth:   2,
+				PointRadius: 3,
+			}
+
+			errs <- DrawGeometry(gc, g, style, viewport.Scale)
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
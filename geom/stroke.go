@@ -0,0 +1,357 @@
+package geom
+
+import (
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+)
+
+// defaultMiterLimit matches the svg default.
+const defaultMiterLimit = 4
+
+// fillStrokePath is FillStroke with the stroke drawn by strokePath.
+func fillStrokePath(gc draw2d.GraphicContext, style Style) {
+	img, ok := imageContext(gc)
+	if !ok || (img.Current.StrokeColor != nil && !style.outlined()) {
+		setMiterLimit(gc, style.MiterLimit)
+		gc.FillStroke()
+		return
+	}
+
+	path := gc.GetPath()
+	gc.Fill()
+
+	strokePath(gc, style, &path)
+}
+
+// strokePath strokes paths and the current path of gc, filling an outline for caps draw2dimg lacks.
+func strokePath(gc draw2d.GraphicContext, style Style, paths ...*draw2d.Path) {
+	img, ok := imageContext(gc)
+	if !ok {
+		setMiterLimit(gc, style.MiterLimit)
+		gc.Stroke(paths...)
+		return
+	}
+
+	// draw2dimg cannot paint a nil colour, strokeOutline skips it instead
+	if img.Current.StrokeColor != nil && !style.outlined() {
+		gc.Stroke(paths...)
+		return
+	}
+
+	current := gc.GetPath()
+	paths = append(paths, &current)
+	gc.BeginPath()
+
+	c := img.Current
+
+	outline := strokeOutline(c, style.MiterLimit, paths)
+	if len(outline) == 0 {
+		return
+	}
+
+	gc.Save()
+	gc.SetFillColor(c.StrokeColor)
+	gc.SetFillRule(draw2d.FillRuleWinding)
+
+	// every piece winds the same way so the overlaps are only painted once
+	for _, p := range outline {
+		gc.MoveTo(p[0][0], p[0][1])
+		for _, pt := range p[1:] {
+			gc.LineTo(pt[0], pt[1])
+		}
+		gc.Close()
+	}
+
+	gc.Fill()
+	gc.Restore()
+}
+
+// strokeOutline returns the polygons covering paths stroked with the line settings of c.
+func strokeOutline(c *draw2dbase.ContextStack, miterLimit float64, paths []*draw2d.Path) [][][]float64 {
+	if c.LineWidth <= 0 || c.StrokeColor == nil {
+		return nil
+	}
+
+	if miterLimit <= 0 {
+		miterLimit = defaultMiterLimit
+	}
+
+	s := &outlineStroker{
+		halfWidth:  c.LineWidth / 2,
+		cap:        c.Cap,
+		join:       c.Join,
+		miterLimit: miterLimit,
+		dashed:     len(c.Dash) > 0,
+	}
+
+	var liner draw2dbase.Flattener = s
+	if len(c.Dash) > 0 {
+		liner = draw2dbase.NewDashConverter(c.Dash, c.DashOffset, s)
+	}
+
+	for _, p := range paths {
+		draw2dbase.Flatten(p, liner, c.Tr.GetScale())
+		s.End()
+	}
+
+	return s.outline()
+}
+
+// outlineStroker is a draw2dbase.Flattener that keeps the lines it is given.
+type outlineStroker struct {
+	halfWidth  float64
+	cap        draw2d.LineCap
+	join       draw2d.LineJoin
+	miterLimit float64
+	dashed     bool
+	lines      [][][]float64
+	closed     []bool
+	current    [][]float64
+}
+
+func (s *outlineStroker) MoveTo(x, y float64) {
+	s.End()
+	s.current = [][]float64{{x, y}}
+}
+
+func (s *outlineStroker) LineTo(x, y float64) {
+	if len(s.current) == 0 {
+		s.current = [][]float64{{x, y}}
+		return
+	}
+
+	s.current = append(s.current, []float64{x, y})
+}
+
+func (s *outlineStroker) LineJoin() {}
+
+func (s *outlineStroker) Close() {
+	// a dash never goes all the way round so is left open
+	s.finish(!s.dashed)
+}
+
+func (s *outlineStroker) End() {
+	s.finish(false)
+}
+
+func (s *outlineStroker) finish(closed bool) {
+	// a lone point is a gap ending, two points in the same place is a dot
+	if len(s.current) > 1 {
+		s.lines = append(s.lines, s.current)
+		s.closed = append(s.closed, closed)
+	}
+
+	s.current = nil
+}
+
+// outline returns the polygons that cover the stroke, all wound counter clockwise.
+func (s *outlineStroker) outline() [][][]float64 {
+	var res [][][]float64
+
+	add := func(p [][]float64) {
+		if signedArea(p) < 0 {
+			r := make([][]float64, len(p))
+			for i, c := range p {
+				r[len(p)-1-i] = c
+			}
+			p = r
+		}
+		res = append(res, p)
+	}
+
+	hw := s.halfWidth
+
+	for i, line := range s.lines {
+		pts := dedupe(line)
+
+		closed := false
+		if s.closed[i] && len(pts) > 3 {
+			first, last := pts[0], pts[len(pts)-1]
+			if first[0] == last[0] && first[1] == last[1] {
+				pts = pts[:len(pts)-1]
+				closed = true
+			}
+		}
+
+		if len(pts) == 1 {
+			p := pts[0]
+
+			switch s.cap {
+			case draw2d.RoundCap:
+				add(circlePolygon(p[0], p[1], hw))
+			case draw2d.SquareCap:
+				add([][]float64{{p[0] - hw, p[1] - hw}, {p[0] + hw, p[1] - hw}, {p[0] + hw, p[1] + hw}, {p[0] - hw, p[1] + hw}})
+			case draw2d.ButtCap:
+			}
+
+			continue
+		}
+
+		n := len(pts)
+		segments := n - 1
+		if closed {
+			segments = n
+		}
+
+		for j := 0; j < segments; j++ {
+			a := pts[j]
+			b := pts[(j+1)%n]
+			nx, ny := normal(a, b, hw)
+
+			add([][]float64{
+				{a[0] + nx, a[1] + ny},
+				{b[0] + nx, b[1] + ny},
+				{b[0] - nx, b[1] - ny},
+				{a[0] - nx, a[1] - ny},
+			})
+		}
+
+		for j := 0; j < n; j++ {
+			if !closed && (j == 0 || j == n-1) {
+				continue
+			}
+
+			if p := s.joinPolygon(pts[(j+n-1)%n], pts[j], pts[(j+1)%n]); p != nil {
+				add(p)
+			}
+		}
+
+		if !closed {
+			if p := s.capPolygon(pts[1], pts[0]); p != nil {
+				add(p)
+			}
+			if p := s.capPolygon(pts[n-2], pts[n-1]); p != nil {
+				add(p)
+			}
+		}
+	}
+
+	return res
+}
+
+// joinPolygon covers the outside of the corner at b.
+func (s *outlineStroker) joinPolygon(a, b, c []float64) [][]float64 {
+	hw := s.halfWidth
+
+	n1x, n1y := normal(a, b, hw)
+	n2x, n2y := normal(b, c, hw)
+
+	cross := (b[0]-a[0])*(c[1]-b[1]) - (b[1]-a[1])*(c[0]-b[0])
+	if cross == 0 {
+		return nil
+	}
+
+	// the outside of the corner is opposite the way the line turns
+	side := -1.0
+	if cross > 0 {
+		side = 1
+	}
+
+	p1 := []float64{b[0] + side*n1x, b[1] + side*n1y}
+	p2 := []float64{b[0] + side*n2x, b[1] + side*n2y}
+
+	// the rasterizer works in 64ths of a pixel so a narrower gap never shows
+	if math.Hypot(p2[0]-p1[0], p2[1]-p1[1]) < 1.0/64 {
+		return nil
+	}
+
+	if s.join == draw2d.RoundJoin {
+		return joinArc(b, p1, p2, hw)
+	}
+
+	if s.join == draw2d.MiterJoin {
+		cos := (n1x*n2x + n1y*n2y) / (hw * hw)
+
+		// the miter is 1 / sin(θ/2) times half the width long
+		ratio := math.Sqrt(2 / (1 + cos))
+		if ratio <= s.miterLimit {
+			mx := (n1x + n2x) / (1 + cos)
+			my := (n1y + n2y) / (1 + cos)
+			tip := []float64{b[0] + side*mx, b[1] + side*my}
+
+			return [][]float64{b, p1, tip, p2}
+		}
+	}
+
+	return [][]float64{b, p1, p2}
+}
+
+// capPolygon covers the end b of a line coming from a.
+func (s *outlineStroker) capPolygon(a, b []float64) [][]float64 {
+	hw := s.halfWidth
+
+	switch s.cap {
+	case draw2d.RoundCap:
+		return circlePolygon(b[0], b[1], hw)
+
+	case draw2d.SquareCap:
+		nx, ny := normal(a, b, hw)
+		// the normal turned a quarter is along the line
+		dx, dy := -ny, nx
+
+		return [][]float64{
+			{b[0] + nx, b[1] + ny},
+			{b[0] + nx + dx, b[1] + ny + dy},
+			{b[0] - nx + dx, b[1] - ny + dy},
+			{b[0] - nx, b[1] - ny},
+		}
+
+	case draw2d.ButtCap:
+	}
+
+	return nil
+}
+
+// normal is perpendicular to a to b and hw long.
+func normal(a, b []float64, hw float64) (float64, float64) {
+	dx := b[0] - a[0]
+	dy := b[1] - a[1]
+	d := math.Hypot(dx, dy)
+
+	return dy * hw / d, -dx * hw / d
+}
+
+// joinArc is the wedge at b rounding the corner from p1 to p2, only sharp turns need many sides.
+func joinArc(b, p1, p2 []float64, r float64) [][]float64 {
+	a1 := math.Atan2(p1[1]-b[1], p1[0]-b[0])
+	sweep := math.Remainder(math.Atan2(p2[1]-b[1], p2[0]-b[0])-a1, 2*math.Pi)
+
+	n := int(math.Ceil(math.Abs(sweep) * r))
+
+	res := [][]float64{b, p1}
+	for i := 1; i < n; i++ {
+		sin, cos := math.Sincos(a1 + sweep*float64(i)/float64(n))
+		res = append(res, []float64{b[0] + r*cos, b[1] + r*sin})
+	}
+
+	return append(res, p2)
+}
+
+func circlePolygon(x, y, r float64) [][]float64 {
+	// enough sides that each is about a pixel long
+	n := int(math.Max(8, math.Ceil(2*math.Pi*r)))
+
+	res := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		res[i] = []float64{x + r*cos, y + r*sin}
+	}
+
+	return res
+}
+
+// dedupe drops points that repeat the one before.
+func dedupe(cs [][]float64) [][]float64 {
+	res := [][]float64{cs[0]}
+
+	for _, c := range cs[1:] {
+		last := res[len(res)-1]
+		if c[0] != last[0] || c[1] != last[1] {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
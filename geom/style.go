@@ -0,0 +1,166 @@
+package geom
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/llgcode/draw2d"
+)
+
+type LineCap int
+
+const (
+	LineCapDefault LineCap = iota
+	LineCapButt
+	LineCapRound
+	LineCapSquare
+)
+
+type LineJoin int
+
+const (
+	LineJoinDefault LineJoin = iota
+	LineJoinMiter
+	LineJoinRound
+	LineJoinBevel
+)
+
+// Style holds everything needed to draw a geometry, zero values keep the defaults.
+type Style struct {
+	FillColor   color.Color
+	StrokeColor color.Color
+	StrokeWidth float64
+	LineWidth   float64
+	PointRadius float64
+	Opacity     float64
+	Dash        []float64
+	DashOffset  float64
+	LineCap     LineCap
+	LineJoin    LineJoin
+	MiterLimit  float64
+	FillRule    draw2d.FillRule
+	Marker      Marker
+	Pattern     FillPattern
+}
+
+func (s Style) fill() color.Color {
+	return s.withOpacity(s.FillColor)
+}
+
+func (s Style) stroke() color.Color {
+	return s.withOpacity(s.StrokeColor)
+}
+
+func (s Style) withOpacity(c color.Color) color.Color {
+	return withOpacity(c, s.Opacity)
+}
+
+func withOpacity(c color.Color, opacity float64) color.Color {
+	if c == nil || opacity <= 0 || opacity >= 1 {
+		return c
+	}
+
+	r, g, b, a := c.RGBA()
+
+	// colours are alpha premultiplied so every channel is scaled
+	return color.RGBA64{
+		R: uint16(float64(r) * opacity),
+		G: uint16(float64(g) * opacity),
+		B: uint16(float64(b) * opacity),
+		A: uint16(float64(a) * opacity),
+	}
+}
+
+// LabelStyle is how text is drawn, a FontSize of zero keeps the size already set on gc.
+type LabelStyle struct {
+	FillColor  color.Color
+	HaloColor  color.Color
+	HaloRadius float64
+	FontSize   float64
+	Opacity    float64
+}
+
+func (s LabelStyle) fill() color.Color {
+	return withOpacity(s.FillColor, s.Opacity)
+}
+
+func (s LabelStyle) halo() color.Color {
+	return withOpacity(s.HaloColor, s.Opacity)
+}
+
+// outlined reports whether the style sets line settings draw2dimg cannot stroke on its own.
+func (s Style) outlined() bool {
+	return s.LineCap != LineCapDefault || s.LineJoin != LineJoinDefault || s.MiterLimit > 0
+}
+
+// applyLine sets the dash, cap and join the style sets on gc.
+func (s Style) applyLine(gc draw2d.GraphicContext) {
+	if len(s.Dash) > 0 {
+		gc.SetLineDash(s.Dash, s.DashOffset)
+	}
+	if s.LineCap != LineCapDefault {
+		gc.SetLineCap(s.LineCap.toDraw2d())
+	}
+	if s.LineJoin != LineJoinDefault {
+		gc.SetLineJoin(s.LineJoin.toDraw2d())
+	}
+}
+
+// Dashed is a dash pattern sized for a line of the given width.
+func Dashed(width float64) []float64 {
+	return []float64{width * 4, width * 2}
+}
+
+// Dotted is a dot pattern sized for a line of the given width.
+func Dotted(width float64) []float64 {
+	return []float64{width, width}
+}
+
+// casingDash grows every dash of the casing by the stroke width so each dash of the line is cased at its ends too.
+func (s Style) casingDash() ([]float64, float64) {
+	if len(s.Dash) == 0 || s.StrokeWidth <= 0 {
+		return s.Dash, s.DashOffset
+	}
+
+	dash := s.Dash
+	if len(dash)%2 == 1 {
+		// an odd pattern repeats to make whole on and off pairs
+		dash = append(append([]float64{}, dash...), dash...)
+	}
+
+	casing := make([]float64, len(dash))
+	for i := 0; i < len(dash); i += 2 {
+		grow := math.Min(s.StrokeWidth, dash[i+1])
+
+		casing[i] = dash[i] + grow
+		casing[i+1] = dash[i+1] - grow
+	}
+
+	return casing, s.DashOffset + s.StrokeWidth/2
+}
+
+func (c LineCap) toDraw2d() draw2d.LineCap {
+	switch c {
+	case LineCapButt:
+		return draw2d.ButtCap
+	case LineCapSquare:
+		return draw2d.SquareCap
+	case LineCapDefault, LineCapRound:
+		return draw2d.RoundCap
+	}
+
+	return draw2d.RoundCap
+}
+
+func (j LineJoin) toDraw2d() draw2d.LineJoin {
+	switch j {
+	case LineJoinMiter:
+		return draw2d.MiterJoin
+	case LineJoinBevel:
+		return draw2d.BevelJoin
+	case LineJoinDefault, LineJoinRound:
+		return draw2d.RoundJoin
+	}
+
+	return draw2d.RoundJoin
+}
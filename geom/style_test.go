@@ -0,0 +1,79 @@
+package geom
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+func TestStyleOpacity(t *testing.T) {
+	tests := map[string]struct {
+		style    Style
+		expected color.Color
+	}{
+		"unset": {
+			style:    Style{FillColor: blue},
+			expected: blue,
+		},
+		"opaque": {
+			style:    Style{FillColor: blue, Opacity: 1},
+			expected: blue,
+		},
+		"half": {
+			style:    Style{FillColor: white, Opacity: 0.5},
+			expected: color.RGBA64{R: 0x7FFF, G: 0x7FFF, B: 0x7FFF, A: 0x7FFF},
+		},
+		"nil": {
+			style:    Style{Opacity: 0.5},
+			expected: nil,
+		},
+	}
+
+	for tname, tt := range tests {
+		actual := tt.style.fill()
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%#v]\nGot [%#v]", tname, tt.expected, actual)
+		}
+	}
+}
+
+func TestStyleApplyLine(t *testing.T) {
+	tests := map[string]struct {
+		style Style
+		cap   draw2d.LineCap
+		join  draw2d.LineJoin
+		dash  []float64
+	}{
+		"unset": {
+			style: Style{},
+			cap:   draw2d.ButtCap,
+			join:  draw2d.BevelJoin,
+			dash:  []float64{2, 2},
+		},
+		"set": {
+			style: Style{LineCap: LineCapSquare, LineJoin: LineJoinMiter, Dash: []float64{4, 1}},
+			cap:   draw2d.SquareCap,
+			join:  draw2d.MiterJoin,
+			dash:  []float64{4, 1},
+		},
+	}
+
+	for tname, tt := range tests {
+		gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+		gc.SetLineCap(draw2d.ButtCap)
+		gc.SetLineJoin(draw2d.BevelJoin)
+		gc.SetLineDash([]float64{2, 2}, 0)
+
+		tt.style.applyLine(gc)
+
+		actual := gc.Current
+		if actual.Cap != tt.cap || actual.Join != tt.join || !reflect.DeepEqual(actual.Dash, tt.dash) {
+			t.Errorf("%v: Expected [%v %v %v]\nGot [%v %v %v]", tname, tt.cap, tt.join, tt.dash, actual.Cap, actual.Join, actual.Dash)
+		}
+	}
+}
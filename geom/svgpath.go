@@ -0,0 +1,325 @@
+package geom
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pathCommand is one absolute segment of an svg path, Points holds the control points then the end point.
+type pathCommand struct {
+	Op     byte
+	Points [][]float64
+}
+
+// parseSVGPath reads svg path data into absolute move, line, curve and close commands, arcs become curves.
+func parseSVGPath(d string) ([]pathCommand, error) {
+	tokens, err := tokenizeSVGPath(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []pathCommand
+	var op byte
+	var x, y, startX, startY float64
+	var lastCtrl, lastQuad []float64
+
+	i := 0
+	next := func(n int) ([]float64, error) {
+		var vals []float64
+
+		for ; n > 0; n-- {
+			if i >= len(tokens) || tokens[i].op != 0 {
+				return nil, fmt.Errorf("path: command %c is missing numbers", op)
+			}
+			vals = append(vals, tokens[i].val)
+			i++
+		}
+
+		return vals, nil
+	}
+
+	for i < len(tokens) {
+		if tokens[i].op != 0 {
+			op = tokens[i].op
+			i++
+		} else if op == 0 {
+			return nil, fmt.Errorf("path: must start with a command, got %v", tokens[i].val)
+		}
+
+		rel := unicode.IsLower(rune(op))
+		abs := func(px, py float64) []float64 {
+			if rel {
+				return []float64{x + px, y + py}
+			}
+			return []float64{px, py}
+		}
+
+		switch unicode.ToUpper(rune(op)) {
+		case 'M':
+			v, err := next(2)
+			if err != nil {
+				return nil, err
+			}
+			p := abs(v[0], v[1])
+			res = append(res, pathCommand{'M', [][]float64{p}})
+			x, y = p[0], p[1]
+			startX, startY = x, y
+			lastCtrl, lastQuad = nil, nil
+
+			// numbers after a move are lines
+			if rel {
+				op = 'l'
+			} else {
+				op = 'L'
+			}
+
+		case 'L':
+			v, err := next(2)
+			if err != nil {
+				return nil, err
+			}
+			p := abs(v[0], v[1])
+			res = append(res, pathCommand{'L', [][]float64{p}})
+			x, y = p[0], p[1]
+			lastCtrl, lastQuad = nil, nil
+
+		case 'H':
+			v, err := next(1)
+			if err != nil {
+				return nil, err
+			}
+			if rel {
+				x += v[0]
+			} else {
+				x = v[0]
+			}
+			res = append(res, pathCommand{'L', [][]float64{{x, y}}})
+			lastCtrl, lastQuad = nil, nil
+
+		case 'V':
+			v, err := next(1)
+			if err != nil {
+				return nil, err
+			}
+			if rel {
+				y += v[0]
+			} else {
+				y = v[0]
+			}
+			res = append(res, pathCommand{'L', [][]float64{{x, y}}})
+			lastCtrl, lastQuad = nil, nil
+
+		case 'C', 'S':
+			n := 6
+			if unicode.ToUpper(rune(op)) == 'S' {
+				n = 4
+			}
+
+			v, err := next(n)
+			if err != nil {
+				return nil, err
+			}
+
+			var c1 []float64
+			if n == 4 {
+				// the first control point mirrors the last one
+				c1 = []float64{x, y}
+				if lastCtrl != nil {
+					c1 = []float64{2*x - lastCtrl[0], 2*y - lastCtrl[1]}
+				}
+				v = append([]float64{0, 0}, v...)
+			} else {
+				c1 = abs(v[0], v[1])
+			}
+
+			c2 := abs(v[2], v[3])
+			p := abs(v[4], v[5])
+			res = append(res, pathCommand{'C', [][]float64{c1, c2, p}})
+			x, y = p[0], p[1]
+			lastCtrl, lastQuad = c2, nil
+
+		case 'Q', 'T':
+			n := 4
+			if unicode.ToUpper(rune(op)) == 'T' {
+				n = 2
+			}
+
+			v, err := next(n)
+			if err != nil {
+				return nil, err
+			}
+
+			var c []float64
+			if n == 2 {
+				// the control point mirrors the last one
+				c = []float64{x, y}
+				if lastQuad != nil {
+					c = []float64{2*x - lastQuad[0], 2*y - lastQuad[1]}
+				}
+				v = append([]float64{0, 0}, v...)
+			} else {
+				c = abs(v[0], v[1])
+			}
+
+			p := abs(v[2], v[3])
+			res = append(res, pathCommand{'Q', [][]float64{c, p}})
+			x, y = p[0], p[1]
+			lastCtrl, lastQuad = nil, c
+
+		case 'A':
+			v, err := next(7)
+			if err != nil {
+				return nil, err
+			}
+			p := abs(v[5], v[6])
+			for _, c := range arcCurves([]float64{x, y}, v[0], v[1], v[2], v[3] != 0, v[4] != 0, p) {
+				res = append(res, pathCommand{'C', c})
+			}
+			x, y = p[0], p[1]
+			lastCtrl, lastQuad = nil, nil
+
+		case 'Z':
+			res = append(res, pathCommand{'Z', nil})
+			x, y = startX, startY
+			lastCtrl, lastQuad = nil, nil
+			op = 0
+
+		default:
+			return nil, fmt.Errorf("path: command %c not supported", op)
+		}
+	}
+
+	return res, nil
+}
+
+// arcCurves returns the svg arc from a to b as cubic curves of at most a quarter turn each.
+func arcCurves(a []float64, rx, ry, rotation float64, large, sweep bool, b []float64) [][][]float64 {
+	if a[0] == b[0] && a[1] == b[1] {
+		return nil
+	}
+
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return [][][]float64{{a, b, b}}
+	}
+
+	sin, cos := math.Sincos(rotation * math.Pi / 180)
+
+	// the midpoint of a and b in the frame of the ellipse
+	dx, dy := (a[0]-b[0])/2, (a[1]-b[1])/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// radii too small to reach b are scaled up until they do
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		k = -k
+	}
+	cx1 := k * rx * y1 / ry
+	cy1 := -k * ry * x1 / rx
+
+	cx := cos*cx1 - sin*cy1 + (a[0]+b[0])/2
+	cy := sin*cx1 + cos*cy1 + (a[1]+b[1])/2
+
+	start := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - start
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	// a point on the ellipse at angle t and the tangent there
+	point := func(t float64) (float64, float64, float64, float64) {
+		st, ct := math.Sincos(t)
+		return cx + rx*cos*ct - ry*sin*st, cy + rx*sin*ct + ry*cos*st, -rx*cos*st - ry*sin*ct, -rx*sin*st + ry*cos*ct
+	}
+
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	h := 4.0 / 3 * math.Tan(step/4)
+
+	var res [][][]float64
+
+	t := start
+	px, py, tx, ty := point(t)
+	for i := 0; i < n; i++ {
+		qx, qy, ux, uy := point(t + step)
+		if i == n-1 {
+			qx, qy = b[0], b[1]
+		}
+
+		res = append(res, [][]float64{{px + h*tx, py + h*ty}, {qx - h*ux, qy - h*uy}, {qx, qy}})
+
+		t += step
+		px, py, tx, ty = qx, qy, ux, uy
+	}
+
+	return res
+}
+
+type pathToken struct {
+	op  byte
+	val float64
+}
+
+func tokenizeSVGPath(d string) ([]pathToken, error) {
+	var res []pathToken
+
+	for i := 0; i < len(d); {
+		c := d[i]
+
+		switch {
+		case c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case strings.IndexByte("MmLlHhVvCcSsQqZzAaTt", c) >= 0:
+			res = append(res, pathToken{op: c})
+			i++
+
+		default:
+			j := i
+			if d[j] == '-' || d[j] == '+' {
+				j++
+			}
+
+			dot := false
+			for j < len(d) && (d[j] >= '0' && d[j] <= '9' || d[j] == '.' && !dot) {
+				if d[j] == '.' {
+					dot = true
+				}
+				j++
+			}
+
+			if j < len(d) && (d[j] == 'e' || d[j] == 'E') {
+				j++
+				if j < len(d) && (d[j] == '-' || d[j] == '+') {
+					j++
+				}
+				for j < len(d) && d[j] >= '0' && d[j] <= '9' {
+					j++
+				}
+			}
+
+			v, err := strconv.ParseFloat(d[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("path: bad number at %v: %w", i, err)
+			}
+
+			res = append(res, pathToken{val: v})
+			i = j
+		}
+	}
+
+	return res, nil
+}
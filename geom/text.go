@@ -0,0 +1,310 @@
+package geom
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dbase"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dpdf"
+	"github.com/llgcode/draw2d/draw2dsvg"
+	geos "github.com/twpayne/go-geos"
+	"golang.org/x/image/font"
+)
+
+// DrawStringWithStyle draws text with the font on gc, leaving the stroke and fill state of gc as it was.
+func DrawStringWithStyle(gc draw2d.GraphicContext, pos []float64, rotation float64, text string, style LabelStyle) error {
+	err := loadFont(gc)
+	if err != nil {
+		return err
+	}
+
+	radians := rotation * (math.Pi / 180)
+
+	gc.Save()
+	defer gc.Restore()
+
+	gc.Translate(pos[0], pos[1])
+	gc.Rotate(radians)
+
+	if style.FontSize > 0 {
+		gc.SetFontSize(style.FontSize)
+	}
+
+	if style.HaloColor != nil && style.HaloRadius > 0 {
+		drawHalo(gc, text, style)
+	}
+
+	fill := style.fill()
+	if fill == nil {
+		fill = color.Black
+	}
+
+	gc.SetFillColor(fill)
+	gc.FillStringAt(text, 0, 0)
+
+	return nil
+}
+
+// TextMetrics is how far text moves the pen and the box its ink covers from the baseline start.
+type TextMetrics struct {
+	Advance float64
+	Bounds  Envelope
+}
+
+// DrawStringWithFace draws kerned text and its halo from f, which sets the size in place of FontSize.
+func DrawStringWithFace(gc draw2d.GraphicContext, pos []float64, f font.Face, rotation float64, text string, style LabelStyle) (TextMetrics, error) {
+	offsets, _, width := runeOffsets(f, text)
+
+	radians := rotation * (math.Pi / 180)
+	sin, cos := math.Sincos(radians)
+
+	gc.Save()
+	defer gc.Restore()
+
+	// every halo goes down before any fill so a halo never covers the rune next to it
+	if style.HaloColor != nil && style.HaloRadius > 0 {
+		gc.SetStrokeColor(style.halo())
+		gc.SetLineWidth(style.HaloRadius * 2)
+		gc.SetLineDash(nil, 0)
+		gc.SetLineCap(draw2d.RoundCap)
+		gc.SetLineJoin(draw2d.RoundJoin)
+
+		for i, char := range []rune(text) {
+			o := offsets[i]
+
+			_, err := drawRune(gc, []float64{pos[0] + o*cos, pos[1] + o*sin}, f, rotation, char, true, false)
+			if err != nil {
+				return TextMetrics{}, err
+			}
+		}
+	}
+
+	if fill := style.fill(); fill != nil {
+		gc.SetFillColor(fill)
+	}
+
+	res := TextMetrics{Advance: width}
+
+	for i, char := range []rune(text) {
+		o := offsets[i]
+
+		m, err := drawRune(gc, []float64{pos[0] + o*cos, pos[1] + o*sin}, f, rotation, char, false, true)
+		if err != nil {
+			return TextMetrics{}, err
+		}
+
+		// whitespace has no ink
+		if m.Bounds.Dx() == 0 || m.Bounds.Dy() == 0 {
+			continue
+		}
+
+		minX := o + m.Bounds.Min[0]
+		maxX := o + m.Bounds.Max[0]
+
+		if res.Bounds.Min == nil {
+			res.Bounds = Envelope{
+				Min: []float64{minX, m.Bounds.Min[1]},
+				Max: []float64{maxX, m.Bounds.Max[1]},
+			}
+			continue
+		}
+
+		res.Bounds.Min[0] = math.Min(res.Bounds.Min[0], minX)
+		res.Bounds.Min[1] = math.Min(res.Bounds.Min[1], m.Bounds.Min[1])
+		res.Bounds.Max[0] = math.Max(res.Bounds.Max[0], maxX)
+		res.Bounds.Max[1] = math.Max(res.Bounds.Max[1], m.Bounds.Max[1])
+	}
+
+	return res, nil
+}
+
+// DrawTextAlongLine draws text centred on a line with each rune turned to follow it.
+func DrawTextAlongLine(gc draw2d.GraphicContext, g *geos.Geom, f font.Face, text string, scale func(x, y float64) (float64, float64)) error {
+	//nolint:exhaustive
+	switch g.TypeID() {
+	case geos.TypeIDLineString, geos.TypeIDLinearRing:
+	default:
+		return fmt.Errorf("geom type not supported %v", g.TypeID())
+	}
+
+	cs := transformCoords(g.CoordSeq().ToCoords(), scale)
+	if len(cs) < 2 {
+		return fmt.Errorf("line must have at least 2 points, got %v", len(cs))
+	}
+
+	n := len(cs) - 1
+	if cs[n][0] < cs[0][0] {
+		r := make([][]float64, len(cs))
+		for i, c := range cs {
+			r[n-i] = c
+		}
+		cs = r
+	}
+
+	offsets, advances, width := runeOffsets(f, text)
+
+	length := lineLength(cs)
+	if width > length {
+		return fmt.Errorf("text %q does not fit the line, needs %v has %v", text, width, length)
+	}
+
+	m := f.Metrics()
+	baseline := float64(m.Ascent-m.Descent) / 64 / 2
+
+	start := (length - width) / 2
+
+	for i, char := range []rune(text) {
+		a := advances[i]
+
+		// the rune takes the angle of the line under its middle
+		x, y, angle := pointAlongLine(cs, start+offsets[i]+a/2)
+
+		radians := angle * (math.Pi / 180)
+		sin, cos := math.Sincos(radians)
+
+		pos := []float64{
+			x - a/2*cos - baseline*sin,
+			y - a/2*sin + baseline*cos,
+		}
+
+		_, err := DrawRune(gc, pos, f, angle, char)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runeOffsets returns where each kerned rune starts along the text and how far it moves the pen.
+func runeOffsets(f font.Face, text string) ([]float64, []float64, float64) {
+	var offsets, advances []float64
+	var pen float64
+
+	prev := rune(-1)
+
+	for _, char := range text {
+		if prev >= 0 {
+			pen += float64(f.Kern(prev, char)) / 64
+		}
+
+		a, _ := f.GlyphAdvance(char)
+
+		offsets = append(offsets, pen)
+		advances = append(advances, float64(a)/64)
+
+		pen += float64(a) / 64
+		prev = char
+	}
+
+	return offsets, advances, pen
+}
+
+// drawGlyph strokes and fills char at the origin of gc, from its outline when f has one.
+func drawGlyph(gc draw2d.GraphicContext, f font.Face, char rune, dr image.Rectangle, mask image.Image, maskp image.Point, stroke, fill bool) error {
+	outline, hasOutline := glyphOutline(f, char)
+
+	if _, ok := imageContext(gc); hasOutline && !ok {
+		if stroke {
+			gc.Stroke(outline)
+		}
+		if fill {
+			gc.Fill(outline)
+		}
+
+		return nil
+	}
+
+	if dr.Empty() {
+		return nil
+	}
+
+	c, ok := current(gc)
+	if !ok {
+		return fmt.Errorf("cannot paint the glyph for %q on %T", char, backend(gc))
+	}
+
+	if stroke && c.LineWidth > 0 {
+		if hasOutline {
+			gc.Stroke(outline)
+		} else {
+			paintGlyph(gc, dr, mask, maskp, c.StrokeColor, c.LineWidth/2)
+		}
+	}
+
+	if fill {
+		paintGlyph(gc, dr, mask, maskp, c.FillColor, 0)
+	}
+
+	return nil
+}
+
+// paintGlyph paints a glyph mask in c, copied around a circle of radius to stroke it.
+func paintGlyph(gc draw2d.GraphicContext, dr image.Rectangle, mask image.Image, maskp image.Point, c color.Color, radius float64) {
+	glyph := image.NewRGBA(dr)
+	draw.DrawMask(glyph, dr, image.NewUniform(c), image.Point{}, mask, maskp, draw.Over)
+
+	if radius <= 0 {
+		drawImage(gc, glyph)
+		return
+	}
+
+	for i := 0; i < haloSteps; i++ {
+		sin, cos := math.Sincos(float64(i) * 2 * math.Pi / haloSteps)
+
+		gc.Save()
+		gc.Translate(radius*cos, radius*sin)
+		drawImage(gc, glyph)
+		gc.Restore()
+	}
+}
+
+// loadFont returns the error draw2d only logs when a context can load neither its font nor the default.
+func loadFont(gc draw2d.GraphicContext) error {
+	var cache draw2d.FontCache
+
+	switch b := backend(gc).(type) {
+	case *draw2dimg.GraphicContext:
+		cache = b.FontCache
+	case *draw2dsvg.GraphicContext:
+		cache = b.FontCache
+	default:
+		return nil
+	}
+
+	_, err := cache.Load(gc.GetFontData())
+	if err != nil {
+		_, err = cache.Load(draw2dbase.DefaultFontData)
+	}
+
+	return err
+}
+
+// haloSteps is how many copies of the text make up a halo when it cannot be stroked.
+const haloSteps = 16
+
+func drawHalo(gc draw2d.GraphicContext, text string, style LabelStyle) {
+	// draw2dpdf fills stroked text so the halo is built from copies set around a circle
+	if _, ok := backend(gc).(*draw2dpdf.GraphicContext); ok {
+		gc.SetFillColor(style.halo())
+
+		for i := 0; i < haloSteps; i++ {
+			sin, cos := math.Sincos(float64(i) * 2 * math.Pi / haloSteps)
+			gc.FillStringAt(text, style.HaloRadius*cos, style.HaloRadius*sin)
+		}
+
+		return
+	}
+
+	gc.SetStrokeColor(style.halo())
+	gc.SetLineWidth(style.HaloRadius * 2)
+	gc.SetLineDash(nil, 0)
+	gc.SetLineCap(draw2d.RoundCap)
+	gc.SetLineJoin(draw2d.RoundJoin)
+	gc.StrokeStringAt(text, 0, 0)
+}
@@ -0,0 +1,398 @@
+package geom
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dsvg"
+	_ "github.com/rockwell-uk/go-text/fonts"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestDrawStringWithFace(t *testing.T) {
+	tests := map[string]struct {
+		face    font.Face
+		text    string
+		advance float64
+		minX    float64
+		maxX    float64
+	}{
+		"rune": {
+			face:    basicfont.Face7x13,
+			text:    "H",
+			advance: 7,
+			minX:    0,
+			maxX:    6,
+		},
+		"string": {
+			face:    basicfont.Face7x13,
+			text:    "HH H",
+			advance: 28,
+			minX:    0,
+			maxX:    27,
+		},
+		"kerning": {
+			face:    kernedFace{Face: basicfont.Face7x13, kern: -2},
+			text:    "HHH",
+			advance: 17,
+			minX:    0,
+			maxX:    16,
+		},
+	}
+
+	for tname, tt := range tests {
+		m := image.NewRGBA(image.Rect(0, 0, 60, 30))
+		draw.Draw(m, m.Bounds(), &image.Uniform{white}, image.Point{0, 0}, draw.Src)
+
+		gc := draw2dimg.NewGraphicContext(m)
+		gc.SetFillColor(black)
+
+		metrics, err := DrawStringWithFace(gc, []float64{10, 20}, tt.face, 0, tt.text, LabelStyle{})
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		if metrics.Advance != tt.advance {
+			t.Errorf("%v: Expected advance [%+v]\nGot [%+v]", tname, tt.advance, metrics.Advance)
+		}
+		if metrics.Bounds.Min[0] != tt.minX || metrics.Bounds.Max[0] != tt.maxX {
+			t.Errorf("%v: Expected x bounds [%+v %+v]\nGot [%+v]", tname, tt.minX, tt.maxX, metrics.Bounds)
+		}
+
+		// ink only inside the bounds, offset by the baseline start
+		inked := 0
+		for y := 0; y < 30; y++ {
+			for x := 0; x < 60; x++ {
+				if m.RGBAAt(x, y) == white {
+					continue
+				}
+				inked++
+
+				fx, fy := float64(x)-10, float64(y)-20
+				if fx < metrics.Bounds.Min[0] || fx >= metrics.Bounds.Max[0] || fy < metrics.Bounds.Min[1] || fy >= metrics.Bounds.Max[1] {
+					t.Errorf("%v: ink outside the bounds at %v,%v", tname, x, y)
+				}
+			}
+		}
+		if inked == 0 {
+			t.Errorf("%v: nothing drawn", tname)
+		}
+	}
+}
+
+func TestDrawStringWithFaceHalo(t *testing.T) {
+	// kerned so the halo of each rune reaches the stem of the next
+	face := kernedFace{Face: basicfont.Face7x13, kern: -2}
+
+	render := func(style LabelStyle) *image.RGBA {
+		m := image.NewRGBA(image.Rect(0, 0, 40, 30))
+		draw.Draw(m, m.Bounds(), &image.Uniform{blue}, image.Point{0, 0}, draw.Src)
+
+		_, err := DrawStringWithFace(draw2dimg.NewGraphicContext(m), []float64{10, 20}, face, 0, "HH", style)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return m
+	}
+
+	plain := render(LabelStyle{FillColor: black})
+	m := render(LabelStyle{FillColor: black, HaloColor: white, HaloRadius: 1})
+
+	y := 15
+	left := -1
+	for x := 0; x < 40; x++ {
+		if m.RGBAAt(x, y) == black {
+			left = x
+			break
+		}
+	}
+	if left < 0 {
+		t.Fatal("no text drawn")
+	}
+
+	tests := map[string]struct {
+		x        int
+		expected color.RGBA
+	}{
+		"fill":    {left, black},
+		"halo":    {left - 1, white},
+		"outside": {left - 3, blue},
+	}
+
+	for tname, tt := range tests {
+		if actual := m.RGBAAt(tt.x, y); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+
+	for x := 0; x < 40; x++ {
+		if plain.RGBAAt(x, y) == black && m.RGBAAt(x, y) != black {
+			t.Errorf("fill covered by a halo at %v,%v", x, y)
+		}
+	}
+}
+
+func TestDrawStringWithFaceHaloSVG(t *testing.T) {
+	f, err := draw2d.GetGlobalFontCache().Load(draw2d.FontData{Name: "regular"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svg := draw2dsvg.NewSvg()
+	gc := draw2dsvg.NewGraphicContext(svg)
+
+	_, err = DrawStringWithFace(gc, []float64{10, 20}, NewOutlineFace(f, 20), 0, "OO", LabelStyle{FillColor: black, HaloColor: white, HaloRadius: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both halos are stroked from the glyph outlines before either rune is filled
+	if len(svg.Groups) != 4 {
+		t.Fatalf("expected 4 groups, got %v", len(svg.Groups))
+	}
+
+	for i, g := range svg.Groups {
+		halo := i < 2
+		if (g.Stroke != "") != halo || (g.Fill != "") == halo {
+			t.Errorf("group %v: expected halo [%v]\nGot [%+v]", i, halo, g.FillStroke)
+		}
+
+		for _, p := range g.Paths {
+			if !strings.Contains(p.Desc, "Q ") {
+				t.Errorf("group %v: expected the glyph outline, got %v", i, p.Desc)
+			}
+		}
+	}
+}
+
+func TestDrawStringWithStyle(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 80, 40))
+	draw.Draw(m, m.Bounds(), &image.Uniform{blue}, image.Point{0, 0}, draw.Src)
+
+	gc := draw2dimg.NewGraphicContext(m)
+	gc.SetFontData(draw2d.FontData{Name: "regular"})
+	gc.SetStrokeColor(blue)
+	gc.SetLineWidth(10)
+
+	style := LabelStyle{
+		FillColor:  black,
+		HaloColor:  white,
+		HaloRadius: 3,
+		FontSize:   20,
+	}
+
+	err := DrawStringWithStyle(gc, []float64{20, 30}, 0, "I", style)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// find the stem of the I on the middle row
+	y := 20
+	left := -1
+	for x := 0; x < 80; x++ {
+		if m.RGBAAt(x, y) == black {
+			left = x
+			break
+		}
+	}
+	if left < 0 {
+		t.Fatal("no text drawn")
+	}
+
+	tests := map[string]struct {
+		x        int
+		expected color.RGBA
+	}{
+		"fill":    {left, black},
+		"halo":    {left - 2, white},
+		"outside": {left - 6, blue},
+	}
+
+	for tname, tt := range tests {
+		if actual := m.RGBAAt(tt.x, y); actual != tt.expected {
+			t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, actual)
+		}
+	}
+
+	if gc.GetFontSize() == style.FontSize {
+		t.Errorf("font size was left changed on the context")
+	}
+}
+
+func TestDrawStringWithStyleMissingFont(t *testing.T) {
+	gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	gc.SetFontData(draw2d.FontData{Name: "missing"})
+
+	err := DrawStringWithStyle(gc, []float64{0, 10}, 0, "I", LabelStyle{})
+	if err == nil {
+		t.Error("expected an error for a font that is not in the cache")
+	}
+}
+
+func TestDrawRuneSVG(t *testing.T) {
+	f, err := draw2d.GetGlobalFontCache().Load(draw2d.FontData{Name: "regular"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	face := NewOutlineFace(f, 20)
+
+	svg := draw2dsvg.NewSvg()
+	gc := draw2dsvg.NewGraphicContext(svg)
+
+	metrics, err := DrawRune(gc, []float64{10, 20}, face, 90, 'O')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	advance, _ := face.GlyphAdvance('O')
+	if metrics.Advance != float64(advance)/64 {
+		t.Errorf("Expected advance [%+v]\nGot [%+v]", float64(advance)/64, metrics.Advance)
+	}
+
+	// the stroke then the fill, both drawn from the curves of the glyph rather than its mask
+	paths := svgPaths(svg.Groups)
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, got %v", paths)
+	}
+
+	for _, p := range paths {
+		if !strings.Contains(p.Desc, "Q ") {
+			t.Errorf("expected the glyph outline, got %v", p.Desc)
+		}
+	}
+}
+
+func TestDrawRuneSVGBitmap(t *testing.T) {
+	svg := draw2dsvg.NewSvg()
+	gc := draw2dsvg.NewGraphicContext(svg)
+	gc.SetFontData(draw2d.FontData{Name: "regular"})
+
+	_, err := DrawRune(gc, []float64{10, 20}, basicfont.Face7x13, 0, 'H')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a face with no outline is painted from its mask, never from the font of gc
+	if len(svg.Groups) == 0 {
+		t.Fatal("nothing drawn")
+	}
+
+	for i, g := range svg.Groups {
+		if g.Image == nil || len(g.Paths) > 0 {
+			t.Errorf("group %v: expected only the glyph image, got %+v", i, g)
+		}
+	}
+}
+
+func svgPaths(groups []*draw2dsvg.Group) []*draw2dsvg.Path {
+	var res []*draw2dsvg.Path
+
+	for _, g := range groups {
+		res = append(res, g.Paths...)
+		res = append(res, svgPaths(g.Groups)...)
+	}
+
+	return res
+}
+
+func TestDrawTextAlongLine(t *testing.T) {
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{100, 100}}
+	viewport := NewViewport(envelope, 100, 100)
+
+	tests := map[string]struct {
+		wkt      string
+		text     string
+		expected []float64
+		fails    bool
+	}{
+		"left to right": {
+			wkt:      "LINESTRING (10 50, 90 50)",
+			text:     "abc",
+			expected: []float64{0, 0, 0},
+		},
+		"right to left": {
+			wkt:      "LINESTRING (90 50, 10 50)",
+			text:     "abc",
+			expected: []float64{0, 0, 0},
+		},
+		"downwards": {
+			wkt:      "LINESTRING (90 90, 10 10)",
+			text:     "abc",
+			expected: []float64{-45, -45, -45},
+		},
+		"bend": {
+			wkt:      "LINESTRING (10 50, 50 50, 50 10)",
+			text:     "abcdefgh",
+			expected: []float64{0, 0, 0, 0, 90, 90, 90, 90},
+		},
+		"too long": {
+			wkt:   "LINESTRING (45 50, 55 50)",
+			text:  "abc",
+			fails: true,
+		},
+	}
+
+	for tname, tt := range tests {
+		g, err := gctx.NewGeomFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gc := &rotationRecorder{GraphicContext: draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 100, 100)))}
+
+		err = DrawTextAlongLine(gc, g, basicfont.Face7x13, tt.text, viewport.Scale)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%v: expected an error", tname)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", tname, err)
+		}
+
+		if len(gc.rotations) != len(tt.expected) {
+			t.Fatalf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, gc.rotations)
+		}
+		for i := range tt.expected {
+			if !closeTo(gc.rotations[i], tt.expected[i]) {
+				t.Errorf("%v: Expected [%+v]\nGot [%+v]", tname, tt.expected, gc.rotations)
+				break
+			}
+		}
+	}
+}
+
+// rotationRecorder keeps the angle of every rotation in degrees.
+type rotationRecorder struct {
+	draw2d.GraphicContext
+	rotations []float64
+}
+
+func (r *rotationRecorder) Unwrap() draw2d.GraphicContext {
+	return r.GraphicContext
+}
+
+func (r *rotationRecorder) Rotate(angle float64) {
+	r.rotations = append(r.rotations, angle/(math.Pi/180))
+	r.GraphicContext.Rotate(angle)
+}
+
+type kernedFace struct {
+	font.Face
+	kern fixed.Int26_6
+}
+
+func (f kernedFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return f.kern * 64
+}
@@ -0,0 +1,84 @@
+package geom
+
+import (
+	"math"
+)
+
+// Viewport maps world coordinates inside Envelope onto an image of Width x Height pixels,
+// Rotation is in degrees about the centre of the image.
+type Viewport struct {
+	Envelope Envelope
+	Width    float64
+	Height   float64
+	Padding  float64
+	Rotation float64
+	FlipY    bool
+	Fit      bool
+}
+
+func NewViewport(e Envelope, width, height float64) Viewport {
+	return Viewport{
+		Envelope: e,
+		Width:    width,
+		Height:   height,
+		FlipY:    true,
+	}
+}
+
+// Scale has the same signature as the scale funcs taken by the draw functions.
+func (v Viewport) Scale(x, y float64) (float64, float64) {
+	ox, oy, w, h := v.frame()
+
+	px := ox + v.Envelope.Px(x)*w
+	py := oy + v.Envelope.Py(y)*h
+
+	if v.FlipY {
+		py = v.Height - py
+	}
+
+	return v.rotate(px, py, v.Rotation)
+}
+
+func (v Viewport) Inverse(px, py float64) (float64, float64) {
+	ox, oy, w, h := v.frame()
+
+	px, py = v.rotate(px, py, -v.Rotation)
+
+	if v.FlipY {
+		py = v.Height - py
+	}
+
+	return v.Envelope.InvPx((px - ox) / w), v.Envelope.InvPy((py - oy) / h)
+}
+
+// frame returns the offset and size of the area the envelope is drawn into.
+func (v Viewport) frame() (float64, float64, float64, float64) {
+	w := v.Width - 2*v.Padding
+	h := v.Height - 2*v.Padding
+
+	if !v.Fit {
+		return v.Padding, v.Padding, w, h
+	}
+
+	s := math.Min(w/v.Envelope.Dx(), h/v.Envelope.Dy())
+	fw := v.Envelope.Dx() * s
+	fh := v.Envelope.Dy() * s
+
+	return v.Padding + (w-fw)/2, v.Padding + (h-fh)/2, fw, fh
+}
+
+func (v Viewport) rotate(x, y, degrees float64) (float64, float64) {
+	if degrees == 0 {
+		return x, y
+	}
+
+	radians := degrees * (math.Pi / 180)
+	sin, cos := math.Sincos(radians)
+
+	cx := v.Width / 2
+	cy := v.Height / 2
+	dx := x - cx
+	dy := y - cy
+
+	return cx + dx*cos - dy*sin, cy + dx*sin + dy*cos
+}
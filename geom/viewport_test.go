@@ -0,0 +1,111 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestViewport(t *testing.T) {
+	envelope := Envelope{Min: []float64{387221.19853198, 410715.07842109}, Max: []float64{392221.19853198, 415715.07842109}}
+	tileWidth := float64(600)
+	tileHeight := float64(600)
+
+	scale := func(x, y float64) (float64, float64) {
+		x = envelope.Px(x) * tileWidth
+		y = tileHeight - (envelope.Py(y) * tileHeight)
+		return x, y
+	}
+
+	v := NewViewport(envelope, tileWidth, tileHeight)
+
+	points := [][]float64{
+		{390380, 413999.9999997685},
+		{387221.19853198, 410715.07842109},
+		{392221.19853198, 415715.07842109},
+	}
+
+	for _, p := range points {
+		ex, ey := scale(p[0], p[1])
+		ax, ay := v.Scale(p[0], p[1])
+
+		if ex != ax || ey != ay {
+			t.Errorf("%v: Expected [%v %v]\nGot [%v %v]", p, ex, ey, ax, ay)
+		}
+	}
+}
+
+func TestViewportFit(t *testing.T) {
+	envelope := Envelope{Min: []float64{0, 0}, Max: []float64{200, 100}}
+
+	v := NewViewport(envelope, 600, 600)
+	v.Fit = true
+	v.Padding = 50
+
+	tests := map[string]struct {
+		point    []float64
+		expected []float64
+	}{
+		"bottom left": {
+			point:    []float64{0, 0},
+			expected: []float64{50, 425},
+		},
+		"top right": {
+			point:    []float64{200, 100},
+			expected: []float64{550, 175},
+		},
+		"centre": {
+			point:    []float64{100, 50},
+			expected: []float64{300, 300},
+		},
+	}
+
+	for tname, tt := range tests {
+		x, y := v.Scale(tt.point[0], tt.point[1])
+
+		if !closeTo(x, tt.expected[0]) || !closeTo(y, tt.expected[1]) {
+			t.Errorf("%v: Expected [%v]\nGot [%v %v]", tname, tt.expected, x, y)
+		}
+	}
+}
+
+func TestViewportInverse(t *testing.T) {
+	envelope := Envelope{Min: []float64{380596, 413770}, Max: []float64{380656, 413830}}
+
+	tests := map[string]Viewport{
+		"plain": NewViewport(envelope, 600, 600),
+		"padded": {
+			Envelope: envelope,
+			Width:    800,
+			Height:   400,
+			Padding:  20,
+			Fit:      true,
+			FlipY:    true,
+		},
+		"rotated": {
+			Envelope: envelope,
+			Width:    600,
+			Height:   600,
+			Rotation: 30,
+			FlipY:    true,
+		},
+		"unflipped": {
+			Envelope: envelope,
+			Width:    300,
+			Height:   600,
+		},
+	}
+
+	p := []float64{380626.5, 413801.25}
+
+	for tname, v := range tests {
+		x, y := v.Inverse(v.Scale(p[0], p[1]))
+
+		if !closeTo(x, p[0]) || !closeTo(y, p[1]) {
+			t.Errorf("%v: Expected [%v]\nGot [%v %v]", tname, p, x, y)
+		}
+	}
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
@@ -3,6 +3,8 @@ module github.com/rockwell-uk/go-geos-draw
 go 1.18
 
 require (
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	github.com/jung-kurt/gofpdf v1.0.0
 	github.com/llgcode/draw2d v0.0.0-20210904075650-80aa0a2a901d
 	github.com/rockwell-uk/go-draw v1.0.0
 	github.com/rockwell-uk/go-text v1.0.0
@@ -10,7 +12,4 @@ require (
 	golang.org/x/image v0.6.0
 )
 
-require (
-	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	github.com/rockwell-uk/csync v1.0.0 // indirect
-)
+require github.com/rockwell-uk/csync v1.0.0 // indirect